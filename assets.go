@@ -0,0 +1,180 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrMissingAsset is returned when StrictAssets is set and the page
+// references an asset that could not be found
+var ErrMissingAsset = errors.New("missing asset")
+
+// FromFS generates a PDF from the entry document of fsys, e.g. files
+// embedded with go:embed. Relative references to images, stylesheets and
+// fonts are served from fsys over a temporary server bound to 127.0.0.1.
+func (g *Generator) FromFS(fsys fs.FS, entry string) ([]byte, error) {
+	entry = strings.TrimPrefix(path.Clean("/"+entry), "/")
+	if _, err := fs.Stat(fsys, entry); err != nil {
+		return nil, fmt.Errorf("failed to open entry document: %w", err)
+	}
+
+	pdf, err := g.fromAssetServer(http.FileServerFS(fsys), entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF from FS: %w", err)
+	}
+
+	return pdf, nil
+}
+
+// FromHTMLWithAssets generates a PDF from HTML content string together with
+// in-memory assets, keyed by the relative path the HTML references them by
+// (e.g. "charts/revenue.png"). Unknown paths get a 404, or fail the render
+// when StrictAssets is set.
+func (g *Generator) FromHTMLWithAssets(htmlContent string, assets map[string][]byte) ([]byte, error) {
+	pdf, err := g.fromAssetServer(assetsHandler(htmlContent, assets), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return pdf, nil
+}
+
+// assetsHandler serves htmlContent at the root and assets below it
+func assetsHandler(htmlContent string, assets map[string][]byte) http.Handler {
+	// Accept keys written as "./logo.png" or "/logo.png" as well
+	files := make(map[string][]byte, len(assets))
+	for name, data := range assets {
+		files[strings.TrimPrefix(path.Clean("/"+name), "/")] = data
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, htmlContent)
+			return
+		}
+
+		data, ok := files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		// The content type comes from the extension, or is sniffed
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+	})
+}
+
+// fromAssetServer serves handler on a temporary local server for the
+// duration of a single render of entry
+func (g *Generator) fromAssetServer(handler http.Handler, entry string) ([]byte, error) {
+	server, err := startAssetServer(handler)
+	if err != nil {
+		return nil, err
+	}
+	defer server.close()
+
+	var buf bytes.Buffer
+	if _, err := g.run(context.Background(), chromedp.Tasks{allowOrigin(server.base), navigateTo(server.url(entry))}, &buf); err != nil {
+		return nil, err
+	}
+
+	if missing := server.missingAssets(); len(missing) > 0 {
+		if g.options.StrictAssets {
+			return nil, fmt.Errorf("%w: %s", ErrMissingAsset, strings.Join(missing, ", "))
+		}
+		g.warn("%s: %s", ErrMissingAsset, strings.Join(missing, ", "))
+	}
+
+	return buf.Bytes(), nil
+}
+
+// assetServer is a throwaway HTTP server on the loopback interface that
+// remembers which requested paths it could not find
+type assetServer struct {
+	server *http.Server
+	base   string
+
+	mu      sync.Mutex
+	missing map[string]bool
+}
+
+// startAssetServer serves handler on a random port of 127.0.0.1, so it is
+// not reachable from other machines
+func startAssetServer(handler http.Handler) (*assetServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start asset server: %w", err)
+	}
+
+	s := &assetServer{
+		base:    "http://" + listener.Addr().String(),
+		missing: make(map[string]bool),
+	}
+	s.server = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			handler.ServeHTTP(rec, r)
+
+			// Chrome asks for a favicon on its own, that's not the page's fault
+			if rec.status == http.StatusNotFound && r.URL.Path != "/favicon.ico" {
+				s.mu.Lock()
+				s.missing[r.URL.Path] = true
+				s.mu.Unlock()
+			}
+		}),
+	}
+	go s.server.Serve(listener)
+
+	return s, nil
+}
+
+// url returns the address of name on the server
+func (s *assetServer) url(name string) string {
+	return s.base + (&url.URL{Path: "/" + name}).EscapedPath()
+}
+
+// missingAssets returns the paths that were requested but not found
+func (s *assetServer) missingAssets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	missing := make([]string, 0, len(s.missing))
+	for p := range s.missing {
+		missing = append(missing, p)
+	}
+	sort.Strings(missing)
+
+	return missing
+}
+
+// close shuts the server down
+func (s *assetServer) close() {
+	s.server.Close()
+}
+
+// statusRecorder remembers the status code written through it
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
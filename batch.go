@@ -0,0 +1,102 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BatchJob is a single document to render as part of a batch. Exactly one
+// of HTML and URL must be set. Every job is rendered on the generator's
+// browser, so a job whose Options set browser-level fields, ChromePath,
+// RemoteURL, ChromeFlags, NoSandbox, AllowFileAccess, ProxyServer or
+// ProxyBypassList, differently from the generator's fails with
+// ErrInvalidOptions.
+type BatchJob struct {
+	ID      string      // Caller-chosen identifier, copied to the result
+	HTML    string      // HTML content to render
+	URL     string      // URL to render
+	Options *PDFOptions // Optional per-job options; the generator's are used when nil
+}
+
+// BatchResult is the outcome of a single batch job
+type BatchResult struct {
+	ID  string
+	PDF []byte
+	Err error
+}
+
+// Batch renders jobs using at most concurrency tabs at a time. See
+// BatchContext.
+func (g *Generator) Batch(jobs []BatchJob, concurrency int) ([]BatchResult, error) {
+	return g.BatchContext(context.Background(), jobs, concurrency)
+}
+
+// BatchContext renders jobs using at most concurrency tabs of one shared
+// browser at a time. A failing job doesn't stop the others; its error is
+// reported in its result. Results are returned in job order. If ctx is
+// cancelled, jobs that haven't started fail with ctx's error, which is also
+// returned.
+func (g *Generator) BatchContext(ctx context.Context, jobs []BatchJob, concurrency int) ([]BatchResult, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	b := g.browser
+	if b == nil {
+		b = newBrowser(g.options)
+		defer b.close()
+	}
+
+	results := make([]BatchResult, len(jobs))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = g.runBatchJob(ctx, b, jobs[i])
+			}
+		}()
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			results[i] = BatchResult{ID: jobs[i].ID, Err: ctx.Err()}
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// runBatchJob renders a single job on the shared browser
+func (g *Generator) runBatchJob(ctx context.Context, b *browser, job BatchJob) BatchResult {
+	result := BatchResult{ID: job.ID}
+
+	part := &Generator{options: g.options, browser: b}
+	if job.Options != nil {
+		part.options = job.Options
+	}
+
+	switch {
+	case !sameBrowser(part.options, g.options):
+		result.Err = fmt.Errorf("%w: batch job sets browser options, such as ChromePath or ProxyServer, that differ from the generator's", ErrInvalidOptions)
+	case job.HTML != "" && job.URL != "":
+		result.Err = errors.New("batch job has both HTML and URL set")
+	case job.URL != "":
+		result.PDF, result.Err = part.FromURLContext(ctx, job.URL)
+	case job.HTML != "":
+		result.PDF, result.Err = part.FromHTMLContext(ctx, job.HTML)
+	default:
+		result.Err = errors.New("batch job has neither HTML nor URL set")
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchBrowserOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ChromePath = "/nonexistent/chrome"
+	g := NewGenerator(opts)
+
+	proxied := opts.Clone()
+	proxied.ProxyServer = "http://proxy.internal:3128"
+	otherChrome := opts.Clone()
+	otherChrome.ChromePath = "/opt/chrome/chrome"
+	unsandboxed := opts.Clone()
+	unsandboxed.NoSandbox = true
+	landscape := opts.Clone()
+	landscape.Landscape = true
+
+	results, err := g.Batch([]BatchJob{
+		{ID: "proxied", HTML: "<p>Hello</p>", Options: proxied},
+		{ID: "other Chrome", HTML: "<p>Hello</p>", Options: otherChrome},
+		{ID: "unsandboxed", HTML: "<p>Hello</p>", Options: unsandboxed},
+		{ID: "landscape", HTML: "<p>Hello</p>", Options: landscape},
+	}, 2)
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+
+	for _, r := range results[:3] {
+		if !errors.Is(r.Err, ErrInvalidOptions) {
+			t.Errorf("job %s error = %v, want ErrInvalidOptions", r.ID, r.Err)
+		}
+	}
+
+	// Render options alone are fine, and the job goes on to launch the
+	// generator's browser
+	if r := results[3]; !errors.Is(r.Err, ErrBrowserStart) {
+		t.Errorf("job %s error = %v, want ErrBrowserStart", r.ID, r.Err)
+	}
+}
@@ -0,0 +1,296 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrRemoteConnect is returned when a remote Chrome cannot be reached
+var ErrRemoteConnect = errors.New("failed to connect to remote Chrome")
+
+// ErrChromeNotFound is returned when no Chrome executable is installed where
+// it is looked for, or at the configured ChromePath
+var ErrChromeNotFound = errors.New("chrome executable not found")
+
+// errBrowserClosed is returned when a tab is requested from a closed browser
+var errBrowserClosed = errors.New("browser is closed")
+
+// browser keeps a single Chrome process alive so that tabs can be opened
+// on it without paying the startup cost on every generation
+type browser struct {
+	options *PDFOptions
+
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+}
+
+// newBrowser creates a browser that is launched lazily on first use
+func newBrowser(options *PDFOptions) *browser {
+	return &browser{options: options}
+}
+
+// newAllocator creates the allocator context Chrome is launched from, or
+// connected to when a remote DevTools endpoint is configured
+func newAllocator(parent context.Context, options *PDFOptions) (context.Context, context.CancelFunc) {
+	if options.RemoteURL != "" {
+		return chromedp.NewRemoteAllocator(parent, options.RemoteURL)
+	}
+
+	opts := append([]chromedp.ExecAllocatorOption{}, chromedp.DefaultExecAllocatorOptions[:]...)
+
+	// Fall back to chromedp's own Chrome lookup when no path is given
+	if options.ChromePath != "" {
+		opts = append(opts, chromedp.ExecPath(options.ChromePath))
+	}
+
+	// Appended after the defaults, so these win when names collide
+	for name, value := range chromeFlags(options) {
+		opts = append(opts, chromedp.Flag(name, value))
+	}
+
+	return chromedp.NewExecAllocator(parent, opts...)
+}
+
+// sameBrowser reports whether options launch, or connect to, a browser the
+// same way as other
+func sameBrowser(options, other *PDFOptions) bool {
+	return options.RemoteURL == other.RemoteURL &&
+		options.ChromePath == other.ChromePath &&
+		reflect.DeepEqual(chromeFlags(options), chromeFlags(other))
+}
+
+// chromeFlags returns the command line flags passed to Chrome on top of
+// chromedp's defaults, with user-provided flags taking precedence
+func chromeFlags(options *PDFOptions) map[string]interface{} {
+	flags := make(map[string]interface{}, len(options.ChromeFlags))
+
+	if options.NoSandbox {
+		flags["no-sandbox"] = true
+		flags["disable-setuid-sandbox"] = true
+	}
+	if options.AllowFileAccess {
+		flags["allow-file-access-from-files"] = true
+	}
+	if options.ProxyServer != "" {
+		flags["proxy-server"] = proxyServer(options.ProxyServer)
+	}
+	if len(options.ProxyBypassList) > 0 {
+		flags["proxy-bypass-list"] = strings.Join(options.ProxyBypassList, ";")
+	}
+
+	for name, value := range options.ChromeFlags {
+		switch value.(type) {
+		case string, bool:
+			flags[name] = value
+		default:
+			// chromedp only understands string and bool values
+			flags[name] = fmt.Sprint(value)
+		}
+	}
+
+	return flags
+}
+
+// checkChromePath makes sure a configured Chrome executable exists and can
+// be run, so a bad path fails fast instead of timing out
+func checkChromePath(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w at %q", ErrChromeNotFound, path)
+	}
+	if err != nil {
+		return fmt.Errorf("chrome executable %q: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("chrome executable %q is a directory", path)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("chrome executable %q is not executable", path)
+	}
+
+	return nil
+}
+
+// chromeLocations are the executables chromedp looks for when no ChromePath
+// is given, in its order
+func chromeLocations() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+		}
+	case "windows":
+		return []string{
+			"chrome",
+			"chrome.exe",
+			`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
+			`C:\Program Files\Google\Chrome\Application\chrome.exe`,
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Google\Chrome\Application\chrome.exe`),
+			filepath.Join(os.Getenv("USERPROFILE"), `AppData\Local\Chromium\Application\chrome.exe`),
+		}
+	default:
+		return []string{
+			"headless_shell",
+			"headless-shell",
+			"chromium",
+			"chromium-browser",
+			"google-chrome",
+			"google-chrome-stable",
+			"google-chrome-beta",
+			"google-chrome-unstable",
+			"/usr/bin/google-chrome",
+			"/usr/local/bin/chrome",
+			"/snap/bin/chromium",
+			"chrome",
+		}
+	}
+}
+
+// findChrome makes sure chromedp will find a Chrome executable, so that a
+// missing one is reported right away with the places that were checked
+func findChrome() error {
+	locations := chromeLocations()
+	for _, path := range locations {
+		if _, err := exec.LookPath(path); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w, checked %s; install Chrome or Chromium, or set its location with ChromePath",
+		ErrChromeNotFound, strings.Join(locations, ", "))
+}
+
+// inContainer reports whether the process appears to run inside a
+// Docker or Podman container
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// startBrowser launches (or connects to) the browser behind ctx and
+// attaches its first tab
+func startBrowser(ctx context.Context, options *PDFOptions) error {
+	if options.RemoteURL == "" {
+		check := findChrome
+		if options.ChromePath != "" {
+			check = func() error { return checkChromePath(options.ChromePath) }
+		}
+		if err := check(); err != nil {
+			return fmt.Errorf("%w: %w", ErrBrowserStart, err)
+		}
+	}
+
+	log := options.logger()
+	switch {
+	case options.RemoteURL != "":
+		log.Debug("connecting to browser", "remote_url", options.RemoteURL)
+	case options.ChromePath != "":
+		log.Debug("launching browser", "chrome_path", options.ChromePath)
+	default:
+		log.Debug("launching browser")
+	}
+	start := time.Now()
+
+	if err := chromedp.Run(ctx); err != nil {
+		if options.RemoteURL != "" {
+			return fmt.Errorf("%w at %s: %w", ErrRemoteConnect, options.RemoteURL, err)
+		}
+		// Chrome exiting right away is usually the sandbox failing to start
+		if !options.NoSandbox && strings.Contains(err.Error(), "chrome failed to start") {
+			return fmt.Errorf("%w (consider NoSandbox() when running in containers): %w", ErrBrowserStart, err)
+		}
+		return fmt.Errorf("%w: %w", ErrBrowserStart, err)
+	}
+
+	log.Info("browser started", "duration", time.Since(start))
+	return nil
+}
+
+// launch starts Chrome if it isn't running yet, relaunching it when the
+// previous process has died
+func (b *browser) launch() (context.Context, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, errBrowserClosed
+	}
+	if b.ctx != nil && b.ctx.Err() == nil {
+		return b.ctx, nil
+	}
+	if b.cancel != nil {
+		b.cancel()
+	}
+
+	allocCtx, allocCancel := newAllocator(context.Background(), b.options)
+	ctx, ctxCancel := chromedp.NewContext(allocCtx)
+	cancel := func() {
+		ctxCancel()
+		allocCancel()
+	}
+
+	if err := startBrowser(ctx, b.options); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	b.ctx, b.cancel = ctx, cancel
+	return ctx, nil
+}
+
+// newTab opens a new tab on the running browser, in a browser context of
+// its own so that cookies, storage and cache don't carry over between
+// renders. Cancelling the tab disposes of its browser context. If the
+// browser turns out to have died, it is relaunched once before giving up.
+func (b *browser) newTab() (context.Context, context.CancelFunc, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var browserCtx context.Context
+		browserCtx, err = b.launch()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ctx, cancel := chromedp.NewContext(browserCtx, chromedp.WithNewBrowserContext())
+		if err = chromedp.Run(ctx); err == nil {
+			return ctx, cancel, nil
+		}
+		cancel()
+
+		if browserCtx.Err() == nil {
+			break
+		}
+	}
+
+	return nil, nil, fmt.Errorf("failed to open tab: %w", err)
+}
+
+// close shuts down the Chrome process, if any
+func (b *browser) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	if b.cancel != nil {
+		b.cancel()
+		b.ctx, b.cancel = nil, nil
+	}
+}
@@ -0,0 +1,53 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestPersistentGeneratorIsolatesTabs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	var mu sync.Mutex
+	var cookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		mu.Unlock()
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "first", Path: "/"})
+		fmt.Fprint(w, `<html><body>Hello</body></html>`)
+	}))
+	defer server.Close()
+
+	g := NewPersistentGenerator(DefaultOptions())
+	defer g.Close()
+
+	for range 2 {
+		_, err := g.FromURL(server.URL)
+		if errors.Is(err, ErrBrowserStart) {
+			t.Skipf("Chrome is not available: %v", err)
+		}
+		if err != nil {
+			t.Fatalf("FromURL() error = %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, cookie := range cookies {
+		if cookie != "" {
+			t.Errorf("request %d sent cookie %q set by an earlier render", i+1, cookie)
+		}
+	}
+}
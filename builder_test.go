@@ -0,0 +1,80 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPermissionsOrder(t *testing.T) {
+	const flags = PermitPrint | PermitPrintHighQuality
+
+	tests := []struct {
+		name      string
+		builder   *OptionsBuilder
+		algorithm EncryptionAlgorithm
+	}{
+		{"after Encrypt", WithOptions().Encrypt("", "owner").Permissions(flags), EncryptAES256},
+		{"before Encrypt", WithOptions().Permissions(flags).Encrypt("", "owner"), EncryptAES256},
+		{"before EncryptRC4", WithOptions().Permissions(flags).EncryptRC4("", "owner"), EncryptRC4},
+		{"kept when encryption changes", WithOptions().Encrypt("", "owner").Permissions(flags).EncryptRC4("", "owner"), EncryptRC4},
+		{"last call wins", WithOptions().Permissions(PermitAll).Encrypt("", "owner").Permissions(flags), EncryptAES256},
+	}
+
+	permitted := flags
+	want := (&EncryptionOptions{Permissions: &permitted}).permissionMask()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.builder.options.Encryption
+			if e == nil {
+				t.Fatal("no encryption configured")
+			}
+			if e.Algorithm != tt.algorithm {
+				t.Errorf("Algorithm = %s, want %s", e.Algorithm, tt.algorithm)
+			}
+			if e.Permissions == nil || *e.Permissions != flags {
+				t.Fatalf("Permissions = %v, want %v", e.Permissions, flags)
+			}
+			if mask := e.permissionMask(); mask != want {
+				t.Errorf("permission mask = %#x, want %#x", mask, want)
+			}
+		})
+	}
+}
+
+func TestPermissionsWithoutEncryption(t *testing.T) {
+	b := WithOptions().Permissions(PermitPrint)
+	if b.options.Encryption != nil {
+		t.Errorf("Permissions configured encryption: %+v", b.options.Encryption)
+	}
+}
+
+func TestBuildChecked(t *testing.T) {
+	g, err := WithOptions().EmulateDevice("Pixel 7").BuildChecked()
+	if err != nil {
+		t.Fatalf("BuildChecked() error = %v", err)
+	}
+	if g.options.Device != "Pixel 7" {
+		t.Errorf("Device = %q, want Pixel 7", g.options.Device)
+	}
+
+	tests := []struct {
+		name    string
+		builder *OptionsBuilder
+	}{
+		{"unknown device", WithOptions().EmulateDevice("iPhone 4")},
+		{"invalid scale", WithOptions().Scale(5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.builder.BuildChecked()
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("BuildChecked() error = %v, want ErrInvalidOptions", err)
+			}
+			if g != nil {
+				t.Error("BuildChecked() returned a generator along with the error")
+			}
+		})
+	}
+}
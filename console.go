@@ -0,0 +1,272 @@
+package htmlgopdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// ConsoleMessage is a message the page logged to its console, or an
+// exception it didn't catch
+type ConsoleMessage struct {
+	Level string // Console method, e.g. "log", "warning" or "error", or "exception"
+	Text  string
+	URL   string // Script the message came from, when known
+	Line  int    // Line in URL, from 1, or 0 when unknown
+}
+
+func (m ConsoleMessage) String() string {
+	if m.URL == "" {
+		return fmt.Sprintf("console %s: %s", m.Level, m.Text)
+	}
+	return fmt.Sprintf("console %s: %s (%s:%d)", m.Level, m.Text, m.URL, m.Line)
+}
+
+// consoleLog collects the console messages of a render for its Result
+type consoleLog struct {
+	mu       sync.Mutex
+	messages []ConsoleMessage
+}
+
+// newConsoleLog returns a log when CaptureConsole is set and the render
+// fills in res, and nil otherwise
+func (g *Generator) newConsoleLog(res *Result) *consoleLog {
+	if !g.options.CaptureConsole || res == nil {
+		return nil
+	}
+	return &consoleLog{}
+}
+
+// add appends m to the log
+func (l *consoleLog) add(m ConsoleMessage) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, m)
+}
+
+// list returns a copy of the messages collected so far
+func (l *consoleLog) list() []ConsoleMessage {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.messages)
+}
+
+// captureConsole listens for the page's console messages and uncaught
+// exceptions. The listener is removed with the tab's context, and calls the
+// handlers on the event goroutine, so no goroutine outlives the render.
+func (g *Generator) captureConsole(messages *consoleLog) chromedp.Action {
+	if !g.options.CaptureConsole && g.options.OnConsole == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			switch ev := ev.(type) {
+			case *runtime.EventConsoleAPICalled:
+				g.console(messages, consoleMessage(ev))
+			case *runtime.EventExceptionThrown:
+				g.console(messages, exceptionMessage(ev.ExceptionDetails))
+			}
+		})
+		return nil
+	})
+}
+
+// console hands a message to OnConsole and messages, and errors to OnWarning
+// when CaptureConsole is set
+func (g *Generator) console(messages *consoleLog, m ConsoleMessage) {
+	messages.add(m)
+	if g.options.OnConsole != nil {
+		g.options.OnConsole(m)
+	}
+	if g.options.CaptureConsole && (m.Level == "error" || m.Level == "assert" || m.Level == "exception") {
+		g.warn("%s", m)
+	}
+}
+
+// JSError is returned when FailOnJSError is set and the page threw an
+// exception it didn't catch
+type JSError struct {
+	Message string // e.g. "ReferenceError: total is not defined"
+	URL     string // Script that threw, when known
+	Line    int    // Line in URL, from 1, or 0 when unknown
+	Stack   string // Call stack, one frame per line, when known
+}
+
+func (e *JSError) Error() string {
+	msg := "page threw an uncaught exception: " + e.Message
+	if e.URL != "" {
+		msg += fmt.Sprintf(" (%s:%d)", e.URL, e.Line)
+	}
+	if e.Stack != "" {
+		msg += "\n" + e.Stack
+	}
+	return msg
+}
+
+// jsErrorWatcher remembers the first uncaught exception that isn't ignored
+type jsErrorWatcher struct {
+	ignore   []string
+	patterns []*regexp.Regexp
+
+	mu  sync.Mutex
+	err *JSError
+}
+
+// newJSErrorWatcher returns a watcher when the options fail on JS errors,
+// and nil otherwise
+func (g *Generator) newJSErrorWatcher() *jsErrorWatcher {
+	if !g.options.FailOnJSError {
+		return nil
+	}
+
+	w := &jsErrorWatcher{ignore: g.options.IgnoreJSErrors}
+	for _, pattern := range g.options.IgnoreJSErrorPatterns {
+		// Checked by validate
+		w.patterns = append(w.patterns, regexp.MustCompile(pattern))
+	}
+	return w
+}
+
+// watch starts recording the page's uncaught exceptions
+func (w *jsErrorWatcher) watch() chromedp.Action {
+	if w == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			if ev, ok := ev.(*runtime.EventExceptionThrown); ok {
+				w.record(jsError(ev.ExceptionDetails))
+			}
+		})
+		return nil
+	})
+}
+
+// record keeps err unless an earlier exception was kept or err is ignored
+func (w *jsErrorWatcher) record(err *JSError) {
+	for _, s := range w.ignore {
+		if strings.Contains(err.Message, s) || strings.Contains(err.URL, s) {
+			return
+		}
+	}
+	for _, re := range w.patterns {
+		if re.MatchString(err.Message) || re.MatchString(err.URL) {
+			return
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+// thrown returns the first exception recorded, or nil
+func (w *jsErrorWatcher) thrown() error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		return nil
+	}
+	return w.err
+}
+
+// check fails with the first exception the page threw, if any
+func (w *jsErrorWatcher) check() chromedp.Action {
+	if w == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		return w.thrown()
+	})
+}
+
+// consoleMessage converts a console API call, joining its arguments with
+// spaces as the console does
+func consoleMessage(ev *runtime.EventConsoleAPICalled) ConsoleMessage {
+	args := make([]string, len(ev.Args))
+	for i, arg := range ev.Args {
+		args[i] = remoteObjectText(arg)
+	}
+
+	m := ConsoleMessage{Level: string(ev.Type), Text: strings.Join(args, " ")}
+	if ev.StackTrace != nil && len(ev.StackTrace.CallFrames) > 0 {
+		frame := ev.StackTrace.CallFrames[0]
+		m.URL, m.Line = frame.URL, int(frame.LineNumber)+1
+	}
+	return m
+}
+
+// exceptionMessage converts an uncaught exception
+func exceptionMessage(details *runtime.ExceptionDetails) ConsoleMessage {
+	m := ConsoleMessage{Level: "exception", Text: details.Text, URL: details.URL, Line: int(details.LineNumber) + 1}
+	if details.Exception != nil && details.Exception.Description != "" {
+		// e.g. "TypeError: x is undefined" with its stack
+		m.Text = details.Exception.Description
+	}
+	if m.URL == "" && details.StackTrace != nil && len(details.StackTrace.CallFrames) > 0 {
+		frame := details.StackTrace.CallFrames[0]
+		m.URL, m.Line = frame.URL, int(frame.LineNumber)+1
+	}
+	return m
+}
+
+// jsError converts an uncaught exception, splitting the stack V8 appends
+// to the description of Error objects from the message
+func jsError(details *runtime.ExceptionDetails) *JSError {
+	m := exceptionMessage(details)
+	message, stack, _ := strings.Cut(m.Text, "\n")
+	err := &JSError{Message: message, URL: m.URL, Line: m.Line, Stack: stack}
+
+	if err.Stack == "" && details.StackTrace != nil {
+		frames := make([]string, len(details.StackTrace.CallFrames))
+		for i, f := range details.StackTrace.CallFrames {
+			name := f.FunctionName
+			if name == "" {
+				name = "<anonymous>"
+			}
+			frames[i] = fmt.Sprintf("    at %s (%s:%d:%d)", name, f.URL, f.LineNumber+1, f.ColumnNumber+1)
+		}
+		err.Stack = strings.Join(frames, "\n")
+	}
+	return err
+}
+
+// remoteObjectText formats a console argument the way the console shows it
+func remoteObjectText(obj *runtime.RemoteObject) string {
+	if len(obj.Value) > 0 {
+		var s string
+		if err := json.Unmarshal(obj.Value, &s); err == nil {
+			return s
+		}
+		return string(obj.Value)
+	}
+	if obj.UnserializableValue != "" {
+		return string(obj.UnserializableValue)
+	}
+	if obj.Description != "" {
+		return obj.Description
+	}
+	return string(obj.Type)
+}
@@ -0,0 +1,68 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// debugCaptureTime is the most time kept back from the timeout to capture
+// the debug artifacts of a render that timed out
+const debugCaptureTime = 5 * time.Second
+
+// debugContext returns the context the render runs in. With DebugDir set
+// it ends before ctx does, so that a render that times out still leaves
+// time to capture the page.
+func (g *Generator) debugContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if g.options.DebugDir == "" || !ok {
+		return context.WithCancel(ctx)
+	}
+
+	reserve := min(debugCaptureTime, time.Until(deadline)/4)
+	return context.WithDeadline(ctx, deadline.Add(-reserve))
+}
+
+// captureDebug writes a screenshot and the DOM of the page in the tab
+// behind ctx to DebugDir, and returns err with the paths of the files. When
+// they can't be captured, e.g. because the browser is gone, err is returned
+// as is.
+func (g *Generator) captureDebug(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		// The tab is closed along with ctx
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, debugCaptureTime)
+	defer cancel()
+
+	var screenshot []byte
+	var dom string
+	if captureErr := chromedp.Run(ctx,
+		chromedp.FullScreenshot(&screenshot, 100),
+		chromedp.Evaluate(`document.documentElement.outerHTML`, &dom),
+	); captureErr != nil {
+		g.warn("failed to capture debug artifacts: %v", captureErr)
+		return err
+	}
+
+	if mkdirErr := os.MkdirAll(g.options.DebugDir, 0755); mkdirErr != nil {
+		g.warn("failed to create debug directory: %v", mkdirErr)
+		return err
+	}
+
+	base := filepath.Join(g.options.DebugDir, "htmlgopdf-"+time.Now().Format("20060102-150405.000000"))
+	screenshotPath, domPath := base+".png", base+".html"
+	for path, data := range map[string][]byte{screenshotPath: screenshot, domPath: []byte(dom)} {
+		if writeErr := os.WriteFile(path, data, 0644); writeErr != nil {
+			g.warn("failed to write debug artifact: %v", writeErr)
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w (screenshot: %s, DOM: %s)", err, screenshotPath, domPath)
+}
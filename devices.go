@@ -0,0 +1,42 @@
+package htmlgopdf
+
+// device describes the screen and browser of a device to emulate
+type device struct {
+	Width             int
+	Height            int
+	DeviceScaleFactor float64
+	Mobile            bool
+	UserAgent         string
+}
+
+// devices are the devices EmulateDevice knows, by name
+var devices = map[string]device{
+	"iPhone 14": {
+		Width:             390,
+		Height:            844,
+		DeviceScaleFactor: 3,
+		Mobile:            true,
+		UserAgent:         "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1",
+	},
+	"Pixel 7": {
+		Width:             412,
+		Height:            915,
+		DeviceScaleFactor: 2.625,
+		Mobile:            true,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 13; Pixel 7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Mobile Safari/537.36",
+	},
+	"iPad Pro": {
+		Width:             1024,
+		Height:            1366,
+		DeviceScaleFactor: 2,
+		Mobile:            true,
+		UserAgent:         "Mozilla/5.0 (iPad; CPU OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1",
+	},
+	"Galaxy S22": {
+		Width:             360,
+		Height:            780,
+		DeviceScaleFactor: 3,
+		Mobile:            true,
+		UserAgent:         "Mozilla/5.0 (Linux; Android 13; SM-S901B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/116.0.0.0 Mobile Safari/537.36",
+	},
+}
@@ -0,0 +1,93 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// Chrome's headless window size
+const (
+	defaultViewportWidth  = 800
+	defaultViewportHeight = 600
+)
+
+// emulate applies the configured device and media emulation to the tab
+// before the page is loaded
+func (g *Generator) emulate() chromedp.Action {
+	var actions chromedp.Tasks
+
+	if g.options.ForcePrintMedia {
+		actions = append(actions, emulation.SetEmulatedMedia().WithMedia("print"))
+	}
+
+	actions = append(actions, g.deviceMetrics())
+
+	d := devices[g.options.Device]
+	if d.Mobile {
+		actions = append(actions, emulation.SetTouchEmulationEnabled(true))
+	}
+	userAgent := d.UserAgent
+	if g.options.UserAgent != "" {
+		userAgent = g.options.UserAgent
+	}
+	if userAgent != "" || g.options.AcceptLanguage != "" || g.options.Platform != "" {
+		actions = append(actions, g.overrideUserAgent(userAgent))
+	}
+
+	return actions
+}
+
+// deviceMetrics sets the configured viewport and device scale factor, if
+// any
+func (g *Generator) deviceMetrics() chromedp.Action {
+	// Explicit viewport settings win over the emulated device's
+	d := devices[g.options.Device]
+	width, height, scale := d.Width, d.Height, d.DeviceScaleFactor
+	if g.options.ViewportWidth > 0 {
+		width = g.options.ViewportWidth
+	}
+	if g.options.ViewportHeight > 0 {
+		height = g.options.ViewportHeight
+	}
+	if g.options.DeviceScaleFactor > 0 {
+		scale = g.options.DeviceScaleFactor
+	}
+
+	if width <= 0 && height <= 0 && scale <= 0 && !d.Mobile {
+		return chromedp.Tasks{}
+	}
+
+	// Keep Chrome's default for the dimension that isn't set
+	if width <= 0 {
+		width = defaultViewportWidth
+	}
+	if height <= 0 {
+		height = defaultViewportHeight
+	}
+	// A zero scale factor keeps the browser's own
+	return emulation.SetDeviceMetricsOverride(int64(width), int64(height), scale, d.Mobile)
+}
+
+// overrideUserAgent sets the User-Agent along with the configured
+// Accept-Language and platform. An empty userAgent keeps Chrome's own.
+func (g *Generator) overrideUserAgent(userAgent string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if userAgent == "" {
+			// The override always replaces the User-Agent, so pass the current one
+			_, _, _, current, _, err := cdpbrowser.GetVersion().Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get User-Agent: %w", err)
+			}
+			userAgent = current
+		}
+
+		return emulation.SetUserAgentOverride(userAgent).
+			WithAcceptLanguage(g.options.AcceptLanguage).
+			WithPlatform(g.options.Platform).
+			Do(ctx)
+	})
+}
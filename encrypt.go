@@ -0,0 +1,385 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// EncryptionAlgorithm is the cipher a PDF is encrypted with
+type EncryptionAlgorithm string
+
+// Encryption algorithms
+const (
+	EncryptAES256 EncryptionAlgorithm = "AES-256" // PDF 1.7 and later readers
+	EncryptRC4    EncryptionAlgorithm = "RC4-128" // For readers limited to PDF 1.4
+)
+
+// PDFPermission is a set of things readers may do with an encrypted PDF
+// without the owner password
+type PDFPermission uint32
+
+// Permissions, as the bits the PDF format uses for them
+const (
+	PermitPrint            PDFPermission = 1 << 2  // Print, at low quality unless PermitPrintHighQuality is set too
+	PermitModify           PDFPermission = 1 << 3  // Change the contents
+	PermitCopy             PDFPermission = 1 << 4  // Copy or extract text and graphics
+	PermitAnnotate         PDFPermission = 1 << 5  // Add or change annotations and fill in forms
+	PermitForms            PDFPermission = 1 << 8  // Fill in forms, even without PermitAnnotate
+	PermitAccessibility    PDFPermission = 1 << 9  // Extract text and graphics for accessibility
+	PermitAssemble         PDFPermission = 1 << 10 // Insert, rotate or delete pages
+	PermitPrintHighQuality PDFPermission = 1 << 11 // Print at full quality
+
+	PermitAll = PermitPrint | PermitModify | PermitCopy | PermitAnnotate |
+		PermitForms | PermitAccessibility | PermitAssemble | PermitPrintHighQuality
+)
+
+// EncryptionOptions protects the PDF with passwords
+type EncryptionOptions struct {
+	UserPassword  string              `json:"userPassword,omitempty"`  // Needed to open the document, empty to open without one
+	OwnerPassword string              `json:"ownerPassword,omitempty"` // Lifts the permission restrictions, the user password when empty
+	Algorithm     EncryptionAlgorithm `json:"algorithm,omitempty"`     // EncryptAES256 when empty
+	Permissions   *PDFPermission      `json:"permissions,omitempty"`   // What readers may do without the owner password, nil for everything
+}
+
+// pdfPadding pads passwords for the RC4 security handler
+var pdfPadding = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// permissionMask returns the P entry of the encryption dictionary: the
+// permitted bits, with the reserved ones set as required
+func (e *EncryptionOptions) permissionMask() int32 {
+	permitted := PermitAll
+	if e.Permissions != nil {
+		permitted = *e.Permissions & PermitAll
+	}
+	return int32(uint32(permitted) | ^uint32(PermitAll)&^3)
+}
+
+// validate checks the algorithm
+func (e *EncryptionOptions) validate() error {
+	switch e.Algorithm {
+	case "", EncryptAES256, EncryptRC4:
+		return nil
+	}
+	return fmt.Errorf("unknown encryption algorithm %q: must be %s or %s", e.Algorithm, EncryptAES256, EncryptRC4)
+}
+
+// applyEncryption encrypts every string and stream of data with the
+// standard security handler, using the passwords set in opts
+func applyEncryption(data []byte, opts *PDFOptions) ([]byte, error) {
+	e := opts.Encryption
+	if e == nil {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt PDF: %w", err)
+	}
+
+	owner := e.OwnerPassword
+	if owner == "" {
+		owner = e.UserPassword
+	}
+
+	// The RC4 keys depend on the document ID, which is never encrypted
+	id, ok := doc.trailer["ID"].(pdfArray)
+	if !ok || len(id) == 0 {
+		sum := md5.Sum(data)
+		id = pdfArray{pdfString(sum[:]), pdfString(sum[:])}
+		doc.trailer["ID"] = id
+	}
+	firstID, _ := id[0].(pdfString)
+
+	var encrypt pdfDict
+	var encryptObject func(num int, data []byte) ([]byte, error)
+	if e.Algorithm == EncryptRC4 {
+		encrypt, encryptObject = rc4Handler([]byte(e.UserPassword), []byte(owner), e.permissionMask(), firstID)
+		if doc.version < "1.4" {
+			doc.version = "1.4"
+		}
+	} else {
+		encrypt, encryptObject, err = aesHandler([]byte(e.UserPassword), []byte(owner), e.permissionMask())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt PDF: %w", err)
+		}
+		doc.version = "1.7"
+		// AES-256 came to PDF 1.7 as an Adobe extension
+		doc.catalog()["Extensions"] = pdfDict{
+			"ADBE": pdfDict{"BaseVersion": pdfName("1.7"), "ExtensionLevel": int64(8)},
+		}
+	}
+
+	for num, obj := range doc.objects {
+		encrypted, err := encryptStrings(obj, func(data []byte) ([]byte, error) {
+			return encryptObject(num, data)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt object %d: %w", num, err)
+		}
+		doc.objects[num] = encrypted
+	}
+	doc.trailer["Encrypt"] = doc.add(encrypt)
+
+	return doc.bytes(), nil
+}
+
+// encryptStrings returns obj with its strings and stream data encrypted
+func encryptStrings(obj pdfObject, encrypt func([]byte) ([]byte, error)) (pdfObject, error) {
+	switch v := obj.(type) {
+	case pdfString:
+		encrypted, err := encrypt(v)
+		return pdfString(encrypted), err
+	case pdfArray:
+		out := make(pdfArray, len(v))
+		for i, item := range v {
+			encrypted, err := encryptStrings(item, encrypt)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = encrypted
+		}
+		return out, nil
+	case pdfDict:
+		out := make(pdfDict, len(v))
+		for k, item := range v {
+			encrypted, err := encryptStrings(item, encrypt)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = encrypted
+		}
+		return out, nil
+	case *pdfStream:
+		dict, err := encryptStrings(v.Dict, encrypt)
+		if err != nil {
+			return nil, err
+		}
+		data, err := encrypt(v.Data)
+		if err != nil {
+			return nil, err
+		}
+		return &pdfStream{Dict: dict.(pdfDict), Data: data}, nil
+	}
+	return obj, nil
+}
+
+// rc4Handler returns the encryption dictionary for 128-bit RC4 (revision
+// 3 of the standard security handler) and the function encrypting data
+// of an object with it
+func rc4Handler(user, owner []byte, permissions int32, id []byte) (pdfDict, func(int, []byte) ([]byte, error)) {
+	const keyLength = 16
+
+	// The O entry: the padded user password encrypted with a key derived
+	// from the owner password
+	sum := md5.Sum(padPassword(owner))
+	for i := 0; i < 50; i++ {
+		sum = md5.Sum(sum[:])
+	}
+	o := rc4Rounds(sum[:keyLength], padPassword(user))
+
+	// The file key
+	h := md5.New()
+	h.Write(padPassword(user))
+	h.Write(o)
+	binary.Write(h, binary.LittleEndian, permissions)
+	h.Write(id)
+	key := h.Sum(nil)
+	for i := 0; i < 50; i++ {
+		next := md5.Sum(key[:keyLength])
+		key = next[:]
+	}
+	key = key[:keyLength]
+
+	// The U entry: the hash of the padding and ID, encrypted with the
+	// file key and padded to 32 bytes
+	h = md5.New()
+	h.Write(pdfPadding)
+	h.Write(id)
+	u := append(rc4Rounds(key, h.Sum(nil)), make([]byte, 16)...)
+
+	dict := pdfDict{
+		"Filter": pdfName("Standard"),
+		"V":      int64(2),
+		"R":      int64(3),
+		"Length": int64(keyLength * 8),
+		"O":      pdfString(o),
+		"U":      pdfString(u),
+		"P":      int64(permissions),
+	}
+
+	return dict, func(num int, data []byte) ([]byte, error) {
+		// Every object has its own key, objects are all written with
+		// generation 0
+		objectKey := md5.Sum(append(append([]byte{}, key...), byte(num), byte(num>>8), byte(num>>16), 0, 0))
+		c, err := rc4.NewCipher(objectKey[:])
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, len(data))
+		c.XORKeyStream(out, data)
+		return out, nil
+	}
+}
+
+// padPassword pads or truncates a password to 32 bytes
+func padPassword(password []byte) []byte {
+	padded := make([]byte, 0, 32)
+	padded = append(padded, password[:min(len(password), 32)]...)
+	return append(padded, pdfPadding[:32-len(padded)]...)
+}
+
+// rc4Rounds encrypts data with key, then 19 more times with key XOR round
+func rc4Rounds(key, data []byte) []byte {
+	out := append([]byte{}, data...)
+	roundKey := make([]byte, len(key))
+	for round := 0; round < 20; round++ {
+		for i := range key {
+			roundKey[i] = key[i] ^ byte(round)
+		}
+		c, _ := rc4.NewCipher(roundKey)
+		c.XORKeyStream(out, out)
+	}
+	return out
+}
+
+// aesHandler returns the encryption dictionary for AES-256 (revision 6 of
+// the standard security handler) and the function encrypting data with it
+func aesHandler(user, owner []byte, permissions int32) (pdfDict, func(int, []byte) ([]byte, error), error) {
+	user, owner = user[:min(len(user), 127)], owner[:min(len(owner), 127)]
+
+	random := make([]byte, 32+16+16+4)
+	if _, err := rand.Read(random); err != nil {
+		return nil, nil, err
+	}
+	key := random[:32]
+	userSalts, ownerSalts, permsRandom := random[32:48], random[48:64], random[64:]
+
+	// The U and UE entries: a hash to check the user password and the
+	// file key encrypted with it
+	u := append(hashR6(user, userSalts[:8], nil), userSalts...)
+	ue, err := aesNoPadding(hashR6(user, userSalts[8:], nil), key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The O and OE entries, which also depend on U
+	o := append(hashR6(owner, ownerSalts[:8], u), ownerSalts...)
+	oe, err := aesNoPadding(hashR6(owner, ownerSalts[8:], u), key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The Perms entry: the permissions encrypted with the file key, so
+	// they can't be changed without it
+	perms := make([]byte, 16)
+	binary.LittleEndian.PutUint32(perms, uint32(permissions))
+	copy(perms[4:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 'T', 'a', 'd', 'b'})
+	copy(perms[12:], permsRandom)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	block.Encrypt(perms, perms)
+
+	dict := pdfDict{
+		"Filter": pdfName("Standard"),
+		"V":      int64(5),
+		"R":      int64(6),
+		"Length": int64(256),
+		"CF": pdfDict{"StdCF": pdfDict{
+			"CFM":       pdfName("AESV3"),
+			"AuthEvent": pdfName("DocOpen"),
+			"Length":    int64(32),
+		}},
+		"StmF":  pdfName("StdCF"),
+		"StrF":  pdfName("StdCF"),
+		"O":     pdfString(o),
+		"U":     pdfString(u),
+		"OE":    pdfString(oe),
+		"UE":    pdfString(ue),
+		"P":     int64(permissions),
+		"Perms": pdfString(perms),
+	}
+
+	return dict, func(_ int, data []byte) ([]byte, error) {
+		// A random IV, prepended to the data, with PKCS#5 padding
+		iv := make([]byte, aes.BlockSize)
+		if _, err := rand.Read(iv); err != nil {
+			return nil, err
+		}
+		pad := aes.BlockSize - len(data)%aes.BlockSize
+		padded := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+		out := make([]byte, len(iv)+len(padded))
+		copy(out, iv)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(out[len(iv):], padded)
+		return out, nil
+	}, nil
+}
+
+// aesNoPadding encrypts data, a multiple of the block size, with AES-256
+// in CBC mode and a zero IV
+func aesNoPadding(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, make([]byte, aes.BlockSize)).CryptBlocks(out, data)
+	return out, nil
+}
+
+// hashR6 computes the password hash of revision 6 of the standard security
+// handler. udata is the U entry when hashing the owner password.
+func hashR6(password, salt, udata []byte) []byte {
+	h := sha256.New()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(udata)
+	k := h.Sum(nil)
+
+	for round := 0; ; {
+		sequence := append(append(append([]byte{}, password...), k...), udata...)
+		k1 := bytes.Repeat(sequence, 64)
+
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+
+		// The first 16 bytes of e as a number, modulo 3, pick the hash
+		var sum int
+		for _, b := range e[:16] {
+			sum += int(b)
+		}
+		var next hash.Hash
+		switch sum % 3 {
+		case 0:
+			next = sha256.New()
+		case 1:
+			next = sha512.New384()
+		default:
+			next = sha512.New()
+		}
+		next.Write(e)
+		k = next.Sum(nil)
+
+		round++
+		if round >= 64 && int(e[len(e)-1]) <= round-32 {
+			break
+		}
+	}
+
+	return k[:32]
+}
@@ -0,0 +1,157 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// envPrefix starts the names of the environment variables options are
+// read from
+const envPrefix = "HTMLGOPDF_"
+
+// envVar is an environment variable MergeEnv reads into a field
+type envVar struct {
+	name string // Without envPrefix
+	set  func(o *PDFOptions, value string) error
+}
+
+// envVars are the environment variables MergeEnv reads, in the
+// order of the fields of PDFOptions
+var envVars = []envVar{
+	{"FORMAT", envString(func(o *PDFOptions) *string { return &o.Format })},
+	{"WIDTH", envFloat(func(o *PDFOptions) *float64 { return &o.Width })},
+	{"HEIGHT", envFloat(func(o *PDFOptions) *float64 { return &o.Height })},
+	{"MARGIN_TOP", envFloat(func(o *PDFOptions) *float64 { return &o.MarginTop })},
+	{"MARGIN_BOTTOM", envFloat(func(o *PDFOptions) *float64 { return &o.MarginBottom })},
+	{"MARGIN_LEFT", envFloat(func(o *PDFOptions) *float64 { return &o.MarginLeft })},
+	{"MARGIN_RIGHT", envFloat(func(o *PDFOptions) *float64 { return &o.MarginRight })},
+	{"LANDSCAPE", envBool(func(o *PDFOptions) *bool { return &o.Landscape })},
+	{"PRINT_BACKGROUND", envBool(func(o *PDFOptions) *bool { return &o.PrintBackground })},
+	{"PAGE_RANGES", envString(func(o *PDFOptions) *string { return &o.PageRanges })},
+	{"VIEWPORT_WIDTH", envInt(func(o *PDFOptions) *int { return &o.ViewportWidth })},
+	{"VIEWPORT_HEIGHT", envInt(func(o *PDFOptions) *int { return &o.ViewportHeight })},
+	{"USER_AGENT", envString(func(o *PDFOptions) *string { return &o.UserAgent })},
+	{"SCALE", envFloat(func(o *PDFOptions) *float64 { return &o.Scale })},
+	{"WAIT_FOR_SELECTOR", envString(func(o *PDFOptions) *string { return &o.WaitForSelector })},
+	{"WAIT_TIME_MS", envMillis(func(o *PDFOptions) *time.Duration { return &o.WaitTime })},
+	{"NETWORK_IDLE_TIMEOUT_MS", envMillis(func(o *PDFOptions) *time.Duration { return &o.NetworkIdleTimeout })},
+	{"NETWORK_IDLE_MS", envMillis(func(o *PDFOptions) *time.Duration { return &o.NetworkIdle })},
+	{"NETWORK_IDLE_MAX_IN_FLIGHT", envInt(func(o *PDFOptions) *int { return &o.NetworkIdleMaxInFlight })},
+	{"NETWORK_IDLE_MAX_WAIT_MS", envMillis(func(o *PDFOptions) *time.Duration { return &o.NetworkIdleMaxWait })},
+	{"TIMEOUT_MS", envMillis(func(o *PDFOptions) *time.Duration { return &o.Timeout })},
+	{"RETRIES", envInt(func(o *PDFOptions) *int { return &o.Retries })},
+	{"RETRY_BACKOFF_MS", envMillis(func(o *PDFOptions) *time.Duration { return &o.RetryBackoff })},
+	{"DEBUG_DIR", envString(func(o *PDFOptions) *string { return &o.DebugDir })},
+	{"CHROME_PATH", envString(func(o *PDFOptions) *string { return &o.ChromePath })},
+	{"REMOTE_URL", envString(func(o *PDFOptions) *string { return &o.RemoteURL })},
+	{"NO_SANDBOX", envBool(func(o *PDFOptions) *bool { return &o.NoSandbox })},
+	{"FAIL_ON_HTTP_ERROR", envBool(func(o *PDFOptions) *bool { return &o.FailOnHTTPError })},
+	{"IGNORE_TLS_ERRORS", envBool(func(o *PDFOptions) *bool { return &o.IgnoreTLSErrors })},
+	{"PROXY_SERVER", envString(func(o *PDFOptions) *string { return &o.ProxyServer })},
+}
+
+// LoadOptionsFromEnv reads options from the HTMLGOPDF_ environment
+// variables listed by ListEnvVars, e.g. HTMLGOPDF_FORMAT or
+// HTMLGOPDF_TIMEOUT_MS. Fields whose variable is unset are left zero. To
+// layer the variables onto other options, use MergeEnv, which unlike Merge
+// also applies false and 0, e.g. HTMLGOPDF_PRINT_BACKGROUND=false. Values
+// that don't parse are reported, as ErrInvalidOptions, along with the
+// options read from the other variables.
+func LoadOptionsFromEnv() (*PDFOptions, error) {
+	return (&PDFOptions{}).MergeEnv()
+}
+
+// MergeEnv returns a copy of o with the fields whose HTMLGOPDF_ variable is
+// set replaced by its value, even when that is false or 0, e.g.
+// DefaultOptions().MergeEnv(). o is not changed. Values that don't parse are
+// reported, as ErrInvalidOptions, along with the options read from the
+// other variables.
+func (o *PDFOptions) MergeEnv() (*PDFOptions, error) {
+	opts := o.Clone()
+
+	var errs []error
+	for _, v := range envVars {
+		value, ok := os.LookupEnv(envPrefix + v.name)
+		if !ok {
+			continue
+		}
+		if err := v.set(opts, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s%s %q: %w", envPrefix, v.name, value, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return opts, fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	return opts, nil
+}
+
+// ListEnvVars returns the names of the environment variables
+// LoadOptionsFromEnv reads, e.g. for tooling that documents a deployment
+func ListEnvVars() []string {
+	names := make([]string, len(envVars))
+	for i, v := range envVars {
+		names[i] = envPrefix + v.name
+	}
+	return names
+}
+
+// envString sets a string field to the value as is
+func envString(field func(*PDFOptions) *string) func(*PDFOptions, string) error {
+	return func(o *PDFOptions, value string) error {
+		*field(o) = value
+		return nil
+	}
+}
+
+// envFloat sets a float field to the parsed value
+func envFloat(field func(*PDFOptions) *float64) func(*PDFOptions, string) error {
+	return func(o *PDFOptions, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("not a number")
+		}
+		*field(o) = f
+		return nil
+	}
+}
+
+// envInt sets an int field to the parsed value
+func envInt(field func(*PDFOptions) *int) func(*PDFOptions, string) error {
+	return func(o *PDFOptions, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("not an integer")
+		}
+		*field(o) = n
+		return nil
+	}
+}
+
+// envBool sets a bool field to the parsed value, e.g. "true", "1" or
+// "false"
+func envBool(field func(*PDFOptions) *bool) func(*PDFOptions, string) error {
+	return func(o *PDFOptions, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("not a boolean")
+		}
+		*field(o) = b
+		return nil
+	}
+}
+
+// envMillis sets a duration field to the value in milliseconds
+func envMillis(field func(*PDFOptions) *time.Duration) func(*PDFOptions, string) error {
+	return func(o *PDFOptions, value string) error {
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("not a whole number of milliseconds")
+		}
+		*field(o) = time.Duration(ms) * time.Millisecond
+		return nil
+	}
+}
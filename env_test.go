@@ -0,0 +1,110 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadOptionsFromEnv(t *testing.T) {
+	t.Setenv("HTMLGOPDF_FORMAT", "Letter")
+	t.Setenv("HTMLGOPDF_MARGIN_TOP", "0.75")
+	t.Setenv("HTMLGOPDF_LANDSCAPE", "true")
+	t.Setenv("HTMLGOPDF_TIMEOUT_MS", "1500")
+
+	opts, err := LoadOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("LoadOptionsFromEnv() error = %v", err)
+	}
+
+	want := &PDFOptions{Format: "Letter", MarginTop: 0.75, Landscape: true, Timeout: 1500 * time.Millisecond}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("LoadOptionsFromEnv() = %+v, want %+v", opts, want)
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	t.Setenv("HTMLGOPDF_PRINT_BACKGROUND", "false")
+	t.Setenv("HTMLGOPDF_MARGIN_TOP", "0")
+	t.Setenv("HTMLGOPDF_WAIT_TIME_MS", "0")
+	t.Setenv("HTMLGOPDF_SCALE", "1.5")
+
+	base := DefaultOptions()
+	opts, err := base.MergeEnv()
+	if err != nil {
+		t.Fatalf("MergeEnv() error = %v", err)
+	}
+
+	want := DefaultOptions()
+	want.PrintBackground = false
+	want.MarginTop = 0
+	want.WaitTime = 0
+	want.Scale = 1.5
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("MergeEnv() = %+v, want %+v", opts, want)
+	}
+	if !reflect.DeepEqual(base, DefaultOptions()) {
+		t.Errorf("MergeEnv() changed the options it was called on: %+v", base)
+	}
+}
+
+func TestMergeEnvInvalid(t *testing.T) {
+	t.Setenv("HTMLGOPDF_SCALE", "large")
+	t.Setenv("HTMLGOPDF_NO_SANDBOX", "maybe")
+	t.Setenv("HTMLGOPDF_FORMAT", "Legal")
+
+	opts, err := DefaultOptions().MergeEnv()
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("MergeEnv() error = %v, want ErrInvalidOptions", err)
+	}
+	for _, name := range []string{"HTMLGOPDF_SCALE", "HTMLGOPDF_NO_SANDBOX"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q doesn't mention %s", err, name)
+		}
+	}
+
+	// The variables that parse are still read
+	if opts.Format != "Legal" {
+		t.Errorf("Format = %q, want Legal", opts.Format)
+	}
+}
+
+func TestListEnvVars(t *testing.T) {
+	names := ListEnvVars()
+	if len(names) != len(envVars) {
+		t.Fatalf("ListEnvVars() has %d names, want %d", len(names), len(envVars))
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if !strings.HasPrefix(name, envPrefix) {
+			t.Errorf("%s doesn't start with %s", name, envPrefix)
+		}
+		if seen[name] {
+			t.Errorf("%s is listed twice", name)
+		}
+		seen[name] = true
+	}
+
+	for _, name := range []string{"HTMLGOPDF_FORMAT", "HTMLGOPDF_TIMEOUT_MS", "HTMLGOPDF_NO_SANDBOX"} {
+		if !seen[name] {
+			t.Errorf("%s is not listed", name)
+		}
+	}
+
+	// Every listed variable is read into a field
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, "1")
+			opts, err := LoadOptionsFromEnv()
+			if err != nil {
+				t.Fatalf("LoadOptionsFromEnv() error = %v", err)
+			}
+			if reflect.DeepEqual(opts, &PDFOptions{}) {
+				t.Errorf("%s=1 set no field", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Error categories, to be checked with errors.Is
+var (
+	// ErrInvalidOptions is returned when the options are rejected before
+	// any browser is launched
+	ErrInvalidOptions = errors.New("invalid options")
+
+	// ErrBrowserStart is returned when Chrome can't be found or launched
+	ErrBrowserStart = errors.New("failed to launch browser")
+
+	// ErrTimeout is returned when generation didn't finish within Timeout
+	// or the caller's deadline
+	ErrTimeout = errors.New("PDF generation timed out")
+
+	// ErrNavigation is matched by every NavigationError, HTTPError and
+	// RedirectError: the page itself couldn't be loaded
+	ErrNavigation = errors.New("navigation failed")
+
+	// ErrChrome is returned when Chrome crashed or the connection to it
+	// was lost during generation
+	ErrChrome = errors.New("chrome failed")
+
+	// ErrRender is returned when the page loaded but preparing or printing
+	// it failed, e.g. an InjectJS snippet threw or the PDF couldn't be
+	// post-processed
+	ErrRender = errors.New("rendering failed")
+)
+
+// NavigationError is returned when the page could not be loaded, e.g.
+// because its host doesn't resolve
+type NavigationError struct {
+	URL   string
+	Cause error
+}
+
+func (e *NavigationError) Error() string {
+	return fmt.Sprintf("failed to navigate to %s: %v", e.URL, e.Cause)
+}
+
+func (e *NavigationError) Unwrap() error {
+	return e.Cause
+}
+
+// Is makes errors.Is(err, ErrNavigation) report true
+func (e *NavigationError) Is(target error) bool {
+	return target == ErrNavigation
+}
+
+// categorizedError puts err in a category without changing its message
+type categorizedError struct {
+	err      error
+	category error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *categorizedError) Unwrap() []error {
+	return []error{e.err, e.category}
+}
+
+// categorize puts err in category, unless it is nil or already there
+func categorize(err, category error) error {
+	if err == nil || errors.Is(err, category) {
+		return err
+	}
+	return &categorizedError{err: err, category: category}
+}
+
+// chromeErrors are failures of Chrome itself rather than of the page
+var chromeErrors = []string{
+	"target crashed",
+	"websocket",
+	"session closed",
+	"browser has disconnected",
+}
+
+// chromeError puts err in ErrChrome when it says Chrome crashed or went
+// away
+func chromeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, chromedp.ErrChannelClosed) || errors.Is(err, chromedp.ErrInvalidWebsocketMessage) {
+		return categorize(err, ErrChrome)
+	}
+	for _, message := range chromeErrors {
+		if strings.Contains(strings.ToLower(err.Error()), message) {
+			return categorize(err, ErrChrome)
+		}
+	}
+	return err
+}
+
+// contextError returns why ctx is done, as an ErrTimeout when its deadline
+// passed
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+	}
+	return ctx.Err()
+}
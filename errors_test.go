@@ -0,0 +1,131 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// sentinels are the error categories a failure can fall in
+var sentinels = []error{ErrInvalidOptions, ErrBrowserStart, ErrTimeout, ErrNavigation, ErrChrome, ErrRender}
+
+func TestErrorCategories(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	invalid := DefaultOptions()
+	invalid.Timeout = 0
+	missingChrome := DefaultOptions()
+	missingChrome.ChromePath = "/nonexistent/chrome"
+
+	failed := errors.New("Evaluate: ReferenceError: x is not defined")
+
+	tests := []struct {
+		name string
+		err  error
+		want error // nil when the error falls in no category
+	}{
+		{"invalid options", func() error {
+			_, err := NewGenerator(invalid).FromHTML("<p>Hello</p>")
+			return err
+		}(), ErrInvalidOptions},
+		{"missing Chrome", func() error {
+			_, err := NewGenerator(missingChrome).FromHTML("<p>Hello</p>")
+			return err
+		}(), ErrBrowserStart},
+		{"deadline exceeded", contextError(expired), ErrTimeout},
+		{"canceled", contextError(canceled), nil},
+		{"navigation error", &NavigationError{URL: "https://example.invalid", Cause: errors.New("net::ERR_NAME_NOT_RESOLVED")}, ErrNavigation},
+		{"HTTP error", fmt.Errorf("failed to generate PDF: %w", &HTTPError{StatusCode: 404, URL: "https://example.com"}), ErrNavigation},
+		{"redirect error", &RedirectError{Reason: "too many redirects", URL: "https://example.com/5"}, ErrNavigation},
+		{"target crashed", chromeError(errors.New("Target crashed")), ErrChrome},
+		{"websocket", chromeError(errors.New("could not read from websocket: EOF")), ErrChrome},
+		{"channel closed", chromeError(fmt.Errorf("print: %w", chromedp.ErrChannelClosed)), ErrChrome},
+		{"invalid websocket message", chromeError(chromedp.ErrInvalidWebsocketMessage), ErrChrome},
+		{"page error", chromeError(failed), nil},
+		{"render", rendering(chromedp.ActionFunc(func(context.Context) error {
+			return failed
+		})).Do(context.Background()), ErrRender},
+		{"render out of time", rendering(chromedp.ActionFunc(func(ctx context.Context) error {
+			return ctx.Err()
+		})).Do(canceled), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("got no error")
+			}
+			for _, sentinel := range sentinels {
+				if got := errors.Is(tt.err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%q, %v) = %v, want %v", tt.err, sentinel, got, !got)
+				}
+			}
+		})
+	}
+}
+
+func TestCategorize(t *testing.T) {
+	if err := categorize(nil, ErrRender); err != nil {
+		t.Errorf("categorize(nil) = %v, want nil", err)
+	}
+
+	cause := errors.New("boom")
+	err := categorize(cause, ErrRender)
+	if err.Error() != cause.Error() {
+		t.Errorf("message = %q, want %q", err, cause)
+	}
+	if !errors.Is(err, cause) || !errors.Is(err, ErrRender) {
+		t.Errorf("%v doesn't match both its cause and its category", err)
+	}
+	if again := categorize(err, ErrRender); again != err {
+		t.Errorf("categorizing twice wrapped the error again")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *PDFOptions)
+	}{
+		{"no paper size", func(o *PDFOptions) { o.Format = ""; o.Width, o.Height = 0, 0 }},
+		{"unknown format", func(o *PDFOptions) { o.Format = "B52" }},
+		{"format and size", func(o *PDFOptions) { o.Width, o.Height = 8.5, 11 }},
+		{"negative margin", func(o *PDFOptions) { o.MarginLeft = -1 }},
+		{"scale too small", func(o *PDFOptions) { o.Scale = 0.05 }},
+		{"negative scale", func(o *PDFOptions) { o.Scale = -1 }},
+		{"scale too large", func(o *PDFOptions) { o.Scale = 3 }},
+		{"no timeout", func(o *PDFOptions) { o.Timeout = 0 }},
+		{"negative network idle", func(o *PDFOptions) { o.NetworkIdle = -time.Second }},
+		{"negative images timeout", func(o *PDFOptions) { o.WaitForImagesTimeout = -time.Second }},
+		{"bad page ranges", func(o *PDFOptions) { o.PageRanges = "3-1" }},
+	}
+
+	if err := DefaultOptions().Validate(); err != nil {
+		t.Fatalf("default options are invalid: %v", err)
+	}
+
+	// Like DeviceScaleFactor, a zero Scale keeps Chrome's default
+	unscaled := DefaultOptions()
+	unscaled.Scale = 0
+	if err := unscaled.Validate(); err != nil {
+		t.Errorf("options without a scale are invalid: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := DefaultOptions()
+			tt.modify(o)
+			err := o.Validate()
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Validate() = %v, want ErrInvalidOptions", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FromHTMLToFile generates a PDF from HTML content string and writes it to
+// path. The file is written atomically, so it is never left half-written on
+// error. FileMode and CreateDirs control how it is created.
+func (g *Generator) FromHTMLToFile(htmlContent, path string) error {
+	return g.writeFileAtomic(path, func(w io.Writer) error {
+		return g.WritePDFFromHTML(context.Background(), htmlContent, w)
+	})
+}
+
+// FromURLToFile generates a PDF from a URL and writes it to path. The file
+// is written atomically, so it is never left half-written on error.
+// FileMode and CreateDirs control how it is created.
+func (g *Generator) FromURLToFile(url, path string) error {
+	return g.writeFileAtomic(path, func(w io.Writer) error {
+		return g.WritePDFFromURL(context.Background(), url, w)
+	})
+}
+
+// ToFile is the same as FromHTMLToFile
+func (g *Generator) ToFile(htmlContent, path string) error {
+	return g.FromHTMLToFile(htmlContent, path)
+}
+
+// URLToFile is the same as FromURLToFile
+func (g *Generator) URLToFile(url, path string) error {
+	return g.FromURLToFile(url, path)
+}
+
+// fileURL returns the file:// URL of an existing local file. Spaces and
+// non-ASCII characters are percent-encoded, and Windows drive letters and
+// UNC shares are mapped the way Chrome expects.
+func fileURL(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to open HTML file: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("HTML file %q is a directory", abs)
+	}
+
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	if strings.HasPrefix(u.Path, "//") {
+		// \\server\share\doc.html becomes file://server/share/doc.html
+		host, rest, _ := strings.Cut(strings.TrimPrefix(u.Path, "//"), "/")
+		u.Host, u.Path = host, "/"+rest
+	} else if !strings.HasPrefix(u.Path, "/") {
+		// C:/doc.html becomes file:///C:/doc.html
+		u.Path = "/" + u.Path
+	}
+
+	return u.String(), nil
+}
+
+// writeFileAtomic writes to a temporary file next to path and renames it
+// into place once write succeeds
+func (g *Generator) writeFileAtomic(path string, write func(w io.Writer) error) (err error) {
+	if g.options.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	mode := g.options.FileMode
+	if mode == 0 {
+		mode = 0644
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	// CreateTemp always uses 0600
+	if err = tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to write PDF file: %w", err)
+	}
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to write PDF file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write PDF file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write PDF file: %w", err)
+	}
+
+	return nil
+}
@@ -1,9 +1,34 @@
 package htmlgopdf
 
+// Paper formats, see the switch in printParams for their sizes
 const (
-	FormatA4      = "A4"
+	FormatA0      = "A0"
+	FormatA1      = "A1"
+	FormatA2      = "A2"
 	FormatA3      = "A3"
+	FormatA4      = "A4"
+	FormatA5      = "A5"
+	FormatA6      = "A6"
+	FormatB4      = "B4"
+	FormatB5      = "B5"
+	FormatC4      = "C4"
+	FormatC5      = "C5"
+	FormatDL      = "DL"
 	FormatLetter  = "Letter"
 	FormatLegal   = "Legal"
 	FormatTabloid = "Tabloid"
+
+	// FormatCustom uses Width and Height instead of a named format
+	FormatCustom = "Custom"
 )
+
+// knownFormat reports whether format is one of the named paper formats
+func knownFormat(format string) bool {
+	switch format {
+	case FormatA0, FormatA1, FormatA2, FormatA3, FormatA4, FormatA5, FormatA6,
+		FormatB4, FormatB5, FormatC4, FormatC5, FormatDL,
+		FormatLetter, FormatLegal, FormatTabloid:
+		return true
+	}
+	return false
+}
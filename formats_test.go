@@ -0,0 +1,55 @@
+package htmlgopdf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSizeConversions(t *testing.T) {
+	tests := []struct {
+		name                  string
+		size                  func(b *OptionsBuilder) *OptionsBuilder
+		wantWidth, wantHeight float64 // Inches, to 4 decimal places
+	}{
+		{"A4 in mm", func(b *OptionsBuilder) *OptionsBuilder { return b.MillimeterSize(210, 297) }, 8.2677, 11.6929},
+		{"Letter in mm", func(b *OptionsBuilder) *OptionsBuilder { return b.MillimeterSize(215.9, 279.4) }, 8.5, 11},
+		{"label in mm", func(b *OptionsBuilder) *OptionsBuilder { return b.MillimeterSize(100, 150) }, 3.9370, 5.9055},
+		{"one mm", func(b *OptionsBuilder) *OptionsBuilder { return b.MillimeterSize(1, 1) }, 0.0394, 0.0394},
+		{"Letter in pt", func(b *OptionsBuilder) *OptionsBuilder { return b.PointSize(612, 792) }, 8.5, 11},
+		{"A4 in pt", func(b *OptionsBuilder) *OptionsBuilder { return b.PointSize(595.28, 841.89) }, 8.2678, 11.6929},
+		{"one pt", func(b *OptionsBuilder) *OptionsBuilder { return b.PointSize(1, 1) }, 0.0139, 0.0139},
+		{"inches", func(b *OptionsBuilder) *OptionsBuilder { return b.Size(8.27, 11.69) }, 8.27, 11.69},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.size(WithOptions()).options
+
+			if math.Abs(opts.Width-tt.wantWidth) >= 0.00005 {
+				t.Errorf("Width = %.6f, want %.4f", opts.Width, tt.wantWidth)
+			}
+			if math.Abs(opts.Height-tt.wantHeight) >= 0.00005 {
+				t.Errorf("Height = %.6f, want %.4f", opts.Height, tt.wantHeight)
+			}
+			if opts.Format != FormatCustom {
+				t.Errorf("Format = %q, want %q", opts.Format, FormatCustom)
+			}
+			if err := opts.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestKnownFormat(t *testing.T) {
+	for _, format := range []string{FormatA4, FormatLetter, FormatDL, FormatTabloid} {
+		if !knownFormat(format) {
+			t.Errorf("knownFormat(%q) = false, want true", format)
+		}
+	}
+	for _, format := range []string{"", FormatCustom, "a4", "A7"} {
+		if knownFormat(format) {
+			t.Errorf("knownFormat(%q) = true, want false", format)
+		}
+	}
+}
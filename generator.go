@@ -1,18 +1,33 @@
 package htmlgopdf
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
+	"html/template"
+	"io"
+	"os"
 	"time"
 
+	cdpio "github.com/chromedp/cdproto/io"
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 )
 
+// ErrInputTooLarge is returned when HTML read from a reader exceeds
+// MaxInputSize
+var ErrInputTooLarge = errors.New("HTML input too large")
+
 // Generator handles PDF generation from HTML content
 type Generator struct {
 	options *PDFOptions
+	browser *browser // nil unless the generator is persistent
+
+	// Set by OptionsBuilder.Template
+	template     *template.Template
+	templateData any
 }
 
 // NewGenerator creates a new PDF generator with the given options
@@ -25,79 +40,516 @@ func NewGenerator(options *PDFOptions) *Generator {
 	}
 }
 
+// NewPersistentGenerator creates a PDF generator that launches Chrome once
+// and renders every document in a fresh tab of that browser, each with its
+// own cookies, storage and cache. The browser is relaunched if it dies
+// between calls. Call Close when done with it.
+func NewPersistentGenerator(options *PDFOptions) *Generator {
+	g := NewGenerator(options)
+	g.browser = newBrowser(g.options)
+	return g
+}
+
+// ChromeFlags returns the command line flags the generator passes to Chrome
+// on top of chromedp's defaults, which is useful for debugging launches
+func (g *Generator) ChromeFlags() map[string]interface{} {
+	return chromeFlags(g.options)
+}
+
+// Close shuts down the browser of a persistent generator. It is a no-op
+// for generators that launch a browser per call.
+func (g *Generator) Close() {
+	if g.browser != nil {
+		g.browser.close()
+	}
+}
+
 // FromHTML generates a PDF from HTML content string
 func (g *Generator) FromHTML(htmlContent string) ([]byte, error) {
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), g.options.Timeout)
-	defer cancel()
+	return g.FromHTMLContext(context.Background(), htmlContent)
+}
 
-	// Create a new browser context
-	ctx, cancel = chromedp.NewContext(ctx)
-	defer cancel()
+// FromHTMLContext generates a PDF from HTML content string, aborting when ctx
+// is cancelled. The earlier of ctx's deadline and the configured timeout wins.
+func (g *Generator) FromHTMLContext(ctx context.Context, htmlContent string) ([]byte, error) {
+	res, err := g.htmlResult(ctx, htmlContent)
+	if err != nil {
+		return nil, err
+	}
 
-	var pdfData []byte
-	var err error
+	return res.PDF, nil
+}
 
-	dataURL := "data:text/html;charset=utf-8," + url.PathEscape(htmlContent)
+// WritePDFFromHTML generates a PDF from HTML content string and copies it to
+// w as Chrome produces it, without holding the whole document in memory
+func (g *Generator) WritePDFFromHTML(ctx context.Context, htmlContent string, w io.Writer) error {
+	if _, err := g.run(ctx, g.loadHTML(htmlContent), w); err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
+	}
 
-	// Execute the browser automation
-	err = chromedp.Run(ctx,
-		chromedp.Navigate(dataURL),
-		chromedp.WaitReady("body"),
-		g.waitForConditions(),
-		chromedp.ActionFunc(func(ctx context.Context) error {
-			pdfData, err = g.generatePDF(ctx)
-			return err
-		}),
-	)
+	return nil
+}
 
+// FromHTMLToWriter generates a PDF from HTML content string and copies it to
+// w as Chrome produces it, returning the number of bytes written. On error w
+// may already hold part of the PDF.
+func (g *Generator) FromHTMLToWriter(htmlContent string, w io.Writer) (int64, error) {
+	written, err := g.run(context.Background(), g.loadHTML(htmlContent), w)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+		return written, fmt.Errorf("failed to generate PDF: %w", err)
 	}
 
-	return pdfData, nil
+	return written, nil
+}
+
+// FromReader generates a PDF from HTML content read from r. When
+// MaxInputSize is set, larger input fails with ErrInputTooLarge.
+func (g *Generator) FromReader(r io.Reader) ([]byte, error) {
+	htmlContent, err := g.readHTML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.FromHTML(htmlContent)
+}
+
+// readHTML reads all of r, enforcing the configured input size limit
+func (g *Generator) readHTML(r io.Reader) (string, error) {
+	if max := g.options.MaxInputSize; max > 0 {
+		// Read one byte past the limit to tell "exactly max" from "too large"
+		r = io.LimitReader(r, max+1)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return "", fmt.Errorf("failed to read HTML after %d bytes: %w", buf.Len(), err)
+	}
+
+	if max := g.options.MaxInputSize; max > 0 && int64(buf.Len()) > max {
+		return "", fmt.Errorf("%w: more than %d bytes", ErrInputTooLarge, max)
+	}
+
+	return buf.String(), nil
+}
+
+// FromFile generates a PDF from an HTML file on disk. Chrome loads the file
+// itself, so images and stylesheets next to it resolve as in a browser.
+func (g *Generator) FromFile(path string) ([]byte, error) {
+	fileURL, err := fileURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if _, err := g.run(context.Background(), navigateTo(fileURL), &buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF from file: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromTemplate executes t with data and generates a PDF from the output.
+// Template errors are returned before Chrome is launched.
+func (g *Generator) FromTemplate(t *template.Template, data any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return g.FromHTML(buf.String())
+}
+
+// Render generates a PDF from the template set with OptionsBuilder.Template.
+// Template errors are returned before Chrome is launched.
+func (g *Generator) Render() ([]byte, error) {
+	if g.template == nil {
+		return nil, fmt.Errorf("%w: no template set, see OptionsBuilder.Template", ErrInvalidOptions)
+	}
+
+	return g.FromTemplate(g.template, g.templateData)
 }
 
 // FromURL generates a PDF from a URL
 func (g *Generator) FromURL(url string) ([]byte, error) {
+	return g.FromURLContext(context.Background(), url)
+}
+
+// FromURLContext generates a PDF from a URL, aborting when ctx is cancelled.
+// The earlier of ctx's deadline and the configured timeout wins.
+func (g *Generator) FromURLContext(ctx context.Context, url string) ([]byte, error) {
+	res, err := g.urlResult(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.PDF, nil
+}
+
+// WritePDFFromURL generates a PDF from a URL and copies it to w as Chrome
+// produces it, without holding the whole document in memory
+func (g *Generator) WritePDFFromURL(ctx context.Context, url string, w io.Writer) error {
+	if _, err := g.run(ctx, navigateTo(url), w); err != nil {
+		return fmt.Errorf("failed to generate PDF from URL: %w", err)
+	}
+
+	return nil
+}
+
+// FromURLToWriter generates a PDF from a URL and copies it to w as Chrome
+// produces it, returning the number of bytes written. On error w may already
+// hold part of the PDF.
+func (g *Generator) FromURLToWriter(url string, w io.Writer) (int64, error) {
+	written, err := g.run(context.Background(), navigateTo(url), w)
+	if err != nil {
+		return written, fmt.Errorf("failed to generate PDF from URL: %w", err)
+	}
+
+	return written, nil
+}
+
+// run renders the page and writes the PDF to w, returning the number of
+// bytes written. Transient failures are retried as the options allow.
+func (g *Generator) run(ctx context.Context, navigate chromedp.Action, w io.Writer) (int64, error) {
+	if err := g.options.Validate(); err != nil {
+		return 0, err
+	}
+
+	return g.retry(ctx, func() (int64, error) {
+		return g.attempt(ctx, navigate, w)
+	})
+}
+
+// attempt opens a tab bound to ctx, renders the page on it and writes the
+// PDF to w, returning the number of bytes written
+func (g *Generator) attempt(ctx context.Context, navigate chromedp.Action, w io.Writer) (int64, error) {
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), g.options.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, g.options.Timeout)
 	defer cancel()
 
-	// Create a new browser context
-	ctx, cancel = chromedp.NewContext(ctx)
-	defer cancel()
+	endLaunch := g.span(ctx, spanLaunch)
+	tabCtx, closeTab, err := g.openTab(ctx)
+	endLaunch(err)
+	if err != nil {
+		return 0, err
+	}
+	defer closeTab()
+
+	written, err := g.render(withSpanParent(tabCtx, ctx), navigate, w)
+
+	// Report cancellation by the caller rather than whatever chromedp saw
+	if ctx.Err() != nil {
+		return written, contextError(ctx)
+	}
+
+	return written, err
+}
+
+// openTab opens a tab for a single render: on the persistent browser when
+// there is one, otherwise on a fresh browser that lives as long as ctx
+func (g *Generator) openTab(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if g.browser == nil {
+		// Create the allocator Chrome is launched from
+		allocCtx, allocCancel := newAllocator(ctx, g.options)
+
+		// Create a new browser context
+		browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+		cancel := func() {
+			browserCancel()
+			allocCancel()
+		}
 
-	var pdfData []byte
+		if err := startBrowser(browserCtx, g.options); err != nil {
+			cancel()
+			return nil, nil, err
+		}
+
+		return browserCtx, cancel, nil
+	}
+
+	tabCtx, tabCancel, err := g.browser.newTab()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The tab outlives ctx, so close it once ctx is done
+	stop := context.AfterFunc(ctx, tabCancel)
+
+	return tabCtx, func() {
+		stop()
+		tabCancel()
+	}, nil
+}
+
+// render loads a page in the tab behind ctx and prints it to w
+func (g *Generator) render(ctx context.Context, navigate chromedp.Action, w io.Writer) (int64, error) {
+	var written int64
 	var err error
 
+	i := g.newInterceptor()
+	ctx = i.attach(ctx)
+
+	tracker := g.newNetworkTracker()
+	document := g.newDocumentWatcher()
+	jsErrors := g.newJSErrorWatcher()
+	resources := g.newResourceWatcher()
+
+	log := g.options.logger()
+	res := resultFrom(ctx)
+	var wait *time.Duration
+	if res != nil {
+		wait = &res.WaitDuration
+	}
+
+	messages := g.newConsoleLog(res)
+	if res != nil {
+		// Only the last attempt's messages are kept
+		defer func() { res.Console = messages.list() }()
+	}
+
+	runCtx, cancel := g.debugContext(ctx)
+	defer cancel()
+	navigated := false
+
 	// Execute the browser automation
-	err = chromedp.Run(ctx,
-		chromedp.Navigate(url),
-		chromedp.WaitReady("body"),
-		g.waitForConditions(),
+	err = chromedp.Run(runCtx,
+		i.enable(),
+		g.ignoreCertificateErrors(),
+		g.setCookies(),
+		g.emulate(),
+		g.bypassCSP(),
+		g.captureConsole(messages),
+		tracker.track(),
+		document.watch(),
+		jsErrors.watch(),
+		resources.watch(),
+		hook("BeforeNavigate", g.options.BeforeNavigate),
+		g.stage(StageNavigating),
+		g.traced(spanNavigate, navigation(log, res, navigate)),
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			pdfData, err = g.generatePDF(ctx)
-			return err
+			navigated = true
+			return nil
+		}),
+		document.check(),
+		// Before the waits, so that they see the final layout and the
+		// elements the scripts add
+		g.injectStyles(),
+		g.preloadScripts(),
+		g.stage(StageWaiting),
+		g.traced(spanWait, timed(log, "wait conditions satisfied", wait, chromedp.Tasks{
+			chromedp.WaitReady("body"),
+			g.waitForConditions(tracker),
+		})),
+		jsErrors.check(),
+		resources.check(),
+		rendering(chromedp.Tasks{
+			g.runScripts(),
+			g.removeElements(log, res),
+			g.extraActions(),
+			hook("BeforePrint", g.options.BeforePrint),
+			g.evalBeforePrint(log, res),
+			g.waitForFonts(),
+			g.checkHidden(),
+			g.traced(spanPrint, chromedp.ActionFunc(func(ctx context.Context) error {
+				written, err = g.generatePDF(ctx, w)
+				return err
+			})),
 		}),
 	)
+	if err == nil {
+		g.progress(Progress{Stage: StageDone, Bytes: written})
+		return written, nil
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF from URL: %w", err)
+	if stopped := i.documentError(); stopped != nil {
+		err = stopped
+	} else if thrown := jsErrors.thrown(); thrown != nil {
+		// A wait that timed out is most likely down to the script that threw
+		err = thrown
+	} else if runCtx.Err() != nil && ctx.Err() == nil {
+		// The time kept back for debugging ran out, not ctx
+		err = contextError(runCtx)
+	} else {
+		err = chromeError(proxyError(g.options.ProxyServer, err))
+	}
+
+	if navigated && g.options.DebugDir != "" {
+		err = g.captureDebug(ctx, err)
 	}
 
-	return pdfData, nil
+	return written, err
+}
+
+// rendering runs the actions that prepare and print the loaded page, their
+// errors in ErrRender
+func rendering(action chromedp.Action) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		err := action.Do(ctx)
+		if err != nil && ctx.Err() == nil {
+			// Running out of time is left to ErrTimeout
+			return categorize(err, ErrRender)
+		}
+		return err
+	})
+}
+
+// extraActions runs ExtraActions in order
+func (g *Generator) extraActions() chromedp.Action {
+	actions := g.options.ExtraActions
+	if len(actions) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		for n, action := range actions {
+			if err := action.Do(ctx); err != nil {
+				return fmt.Errorf("action %d failed: %w", n, err)
+			}
+		}
+		return nil
+	})
+}
+
+// hook calls fn, a hook named name, with the tab's context
+func hook(name string, fn func(ctx context.Context) error) chromedp.Action {
+	if fn == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return fmt.Errorf("%s hook failed: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// warn reports a problem that didn't stop the render to OnWarning
+func (g *Generator) warn(format string, args ...any) {
+	if g.options.OnWarning != nil {
+		g.options.OnWarning(fmt.Sprintf(format, args...))
+	}
+}
+
+// setCookies stores the configured cookies in the browser so they are sent
+// from the first request on
+func (g *Generator) setCookies() chromedp.Action {
+	if len(g.options.Cookies) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := network.SetCookies(g.options.Cookies).Do(ctx); err != nil {
+			return fmt.Errorf("failed to set cookies: %w", err)
+		}
+		return nil
+	})
+}
+
+// loadHTML navigates to a blank page and replaces its document with the
+// given HTML. Unlike a data URL this has no size limit and needs no escaping.
+func (g *Generator) loadHTML(htmlContent string) chromedp.Action {
+	htmlContent = withBaseURL(htmlContent, g.options.BaseURL)
+	g.options.logger().Debug("loading HTML content", "bytes", len(htmlContent))
+
+	if g.options.AllowFileAccess {
+		return loadHTMLFile(htmlContent)
+	}
+
+	return chromedp.Tasks{
+		chromedp.Navigate("about:blank"),
+		setDocumentContent(htmlContent),
+		// Wait for subresources, as navigating to a data URL used to
+		chromedp.Poll(`document.readyState === "complete"`, nil, chromedp.WithPollingTimeout(0)),
+	}
+}
+
+// setDocumentContent replaces the main frame's document with the HTML
+func setDocumentContent(htmlContent string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+		return page.SetDocumentContent(tree.Frame.ID, htmlContent).Do(ctx)
+	})
+}
+
+// loadHTMLFile writes the HTML to a temporary file and navigates to it, so
+// that the page may reference other local files. The file is removed once
+// the render is over, whether it succeeded or not.
+func loadHTMLFile(htmlContent string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		f, err := os.CreateTemp("", "htmlgopdf-*.html")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary HTML file: %w", err)
+		}
+		context.AfterFunc(ctx, func() { os.Remove(f.Name()) })
+
+		_, err = io.WriteString(f, htmlContent)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write temporary HTML file: %w", err)
+		}
+
+		fileURL, err := fileURL(f.Name())
+		if err != nil {
+			return err
+		}
+
+		return navigateTo(fileURL).Do(ctx)
+	})
 }
 
 // waitForConditions handles waiting for specific conditions before PDF generation
-func (g *Generator) waitForConditions() chromedp.Action {
+func (g *Generator) waitForConditions(tracker *networkTracker) chromedp.Action {
 	var actions []chromedp.Action
 
+	// Wait for lazy-loaded content to finish loading
+	idleTime := g.options.NetworkIdle
+	if idleTime <= 0 {
+		idleTime = networkIdleTime
+	}
+	switch {
+	case g.options.NetworkIdleTimeout > 0:
+		actions = append(actions, tracker.wait(idleTime, g.options.NetworkIdleTimeout, nil))
+	case g.options.NetworkIdle > 0:
+		maxWait := g.options.NetworkIdleMaxWait
+		if maxWait <= 0 {
+			maxWait = networkIdleMaxWait
+		}
+		actions = append(actions, tracker.wait(idleTime, maxWait, g.warn))
+	}
+
 	// Wait for specific selector if provided
 	if g.options.WaitForSelector != "" {
 		actions = append(actions, chromedp.WaitVisible(g.options.WaitForSelector))
 	}
 
+	if g.options.WaitForHiddenSelector != "" {
+		actions = append(actions, waitForHidden(g.options.WaitForHiddenSelector))
+	}
+
+	if len(g.options.WaitForAllSelectors) > 0 {
+		actions = append(actions, waitForAll(g.options.WaitForAllSelectors))
+	}
+	if len(g.options.WaitForAnySelectors) > 0 {
+		actions = append(actions, waitForAny(g.options.WaitForAnySelectors))
+	}
+
+	// Wait for the page to signal it is ready
+	if g.options.WaitForExpression != "" {
+		actions = append(actions, chromedp.Poll(g.options.WaitForExpression, nil, chromedp.WithPollingTimeout(0)))
+	}
+	if g.options.WaitForFunction != "" {
+		actions = append(actions, waitForFunction(g.options.WaitForFunction, g.options.WaitForFunctionPoll, g.options.WaitForFunctionTimeout))
+	}
+
+	if g.options.WaitForImages {
+		actions = append(actions, g.waitForImages())
+	}
+
 	// Additional wait time
 	if g.options.WaitTime > 0 {
 		actions = append(actions, chromedp.Sleep(g.options.WaitTime))
@@ -110,38 +562,189 @@ func (g *Generator) waitForConditions() chromedp.Action {
 	return chromedp.Tasks(actions)
 }
 
-// generatePDF generates the actual PDF using Chrome DevTools Protocol
-func (g *Generator) generatePDF(ctx context.Context) ([]byte, error) {
+// generatePDF generates the actual PDF using Chrome DevTools Protocol and
+// streams it to w
+func (g *Generator) generatePDF(ctx context.Context, w io.Writer) (int64, error) {
+	log := g.options.logger()
+
+	// The extra round trips are only made when asked for
+	var hs []heading
+	var first []byte
+	var err error
+	if g.options.BuildOutline {
+		if hs, err = g.headings(ctx); err != nil {
+			return 0, err
+		}
+	}
+	if n := g.leadingPages(); n > 0 {
+		if err := addLeadingPages(ctx, n); err != nil {
+			return 0, err
+		}
+		defer removeLeadingPages(ctx)
+	}
+	if skipsFirstPageHeaderFooter(g.options) {
+		if first, err = g.printFirstPage(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	params := g.printParams().WithTransferMode(page.PrintToPDFTransferModeReturnAsStream)
+
+	g.progress(Progress{Stage: StagePrinting})
+	start := time.Now()
+	_, stream, err := params.Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	defer cdpio.Close(stream).Do(ctx)
+	log.Debug("PrintToPDF finished", "duration", time.Since(start))
+
+	if needsPostProcessing(g.options) {
+		// Patching needs the whole document
+		var buf bytes.Buffer
+		if _, err := copyStream(ctx, stream, g.progressWriter(&buf)); err != nil {
+			return 0, fmt.Errorf("failed to read PDF stream: %w", err)
+		}
+
+		data := buf.Bytes()
+		if first != nil {
+			if data, err = replacePages(data, map[int][]byte{0: first}); err != nil {
+				return 0, err
+			}
+		}
+
+		if data, err = applyOutline(data, hs, g.options); err != nil {
+			return 0, err
+		}
+
+		if data, err = postProcess(data, g.options); err != nil {
+			return 0, err
+		}
+
+		n, err := w.Write(data)
+		if err == nil {
+			printed(ctx, log, int64(n), time.Since(start))
+		}
+		return int64(n), err
+	}
+
+	written, err := copyStream(ctx, stream, g.progressWriter(w))
+	if err != nil {
+		return written, fmt.Errorf("failed to stream PDF after %d bytes: %w", written, err)
+	}
+
+	printed(ctx, log, written, time.Since(start))
+	return written, nil
+}
+
+// needsPostProcessing reports whether the options change the PDF after
+// Chrome produced it
+func needsPostProcessing(opts *PDFOptions) bool {
+	return hasMetadata(opts) || opts.Archival != "" || opts.Encryption != nil ||
+		opts.Watermark != nil || opts.ImageWatermark != nil || opts.PageNumbers != nil ||
+		opts.HeaderFooterParity != nil || opts.BuildOutline || skipsFirstPageHeaderFooter(opts)
+}
+
+// postProcess applies the options that patch the PDF Chrome produced
+func postProcess(data []byte, opts *PDFOptions) ([]byte, error) {
+	data, err := applyWatermark(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = applyImageWatermark(data, opts); err != nil {
+		return nil, err
+	}
+
+	if data, err = applyPageNumbers(data, opts); err != nil {
+		return nil, err
+	}
+
+	if data, err = applyHeaderFooterParity(data, opts); err != nil {
+		return nil, err
+	}
+
+	if data, err = applyMetadata(data, opts); err != nil {
+		return nil, err
+	}
+
+	// After the metadata, which the archival XMP repeats
+	if data, err = applyArchival(data, opts); err != nil {
+		return nil, err
+	}
+
+	// Last, as nothing can be changed afterwards without the key
+	return applyEncryption(data, opts)
+}
+
+// printParams builds the PrintToPDF parameters from the options
+func (g *Generator) printParams() *page.PrintToPDFParams {
 	// Build PDF parameters using the correct chromedp API
-	params := page.PrintToPDFParams{
+	params := &page.PrintToPDFParams{
 		PrintBackground:     g.options.PrintBackground,
 		Landscape:           g.options.Landscape,
 		DisplayHeaderFooter: g.options.DisplayHeaderFooter,
 		Scale:               g.options.Scale,
+		PageRanges:          g.printedRanges(),
+		// Chrome places the outline itself where it can, which
+		// applyOutline leaves alone
+		GenerateTaggedPDF:       g.options.BuildOutline,
+		GenerateDocumentOutline: g.options.BuildOutline,
 	}
 
 	// Set paper size based on format or custom dimensions
-	if g.options.Format != "" {
-		switch g.options.Format {
-		case "A4":
-			params.PaperWidth = 8.27  // A4 width in inches
-			params.PaperHeight = 11.7 // A4 height in inches
-		case "A3":
-			params.PaperWidth = 11.7
-			params.PaperHeight = 16.5
-		case "Letter":
-			params.PaperWidth = 8.5
-			params.PaperHeight = 11.0
-		case "Legal":
-			params.PaperWidth = 8.5
-			params.PaperHeight = 14.0
-		case "Tabloid":
-			params.PaperWidth = 11.0
-			params.PaperHeight = 17.0
-		}
-	} else if g.options.Width > 0 && g.options.Height > 0 {
-		params.PaperWidth = g.options.Width
-		params.PaperHeight = g.options.Height
+	switch g.options.Format {
+	case FormatA0:
+		params.PaperWidth = 33.1
+		params.PaperHeight = 46.8
+	case FormatA1:
+		params.PaperWidth = 23.4
+		params.PaperHeight = 33.1
+	case FormatA2:
+		params.PaperWidth = 16.5
+		params.PaperHeight = 23.4
+	case FormatA3:
+		params.PaperWidth = 11.7
+		params.PaperHeight = 16.5
+	case FormatA4:
+		params.PaperWidth = 8.27  // A4 width in inches
+		params.PaperHeight = 11.7 // A4 height in inches
+	case FormatA5:
+		params.PaperWidth = 5.83
+		params.PaperHeight = 8.27
+	case FormatA6:
+		params.PaperWidth = 4.13
+		params.PaperHeight = 5.83
+	case FormatB4:
+		params.PaperWidth = 9.84
+		params.PaperHeight = 13.9
+	case FormatB5:
+		params.PaperWidth = 6.93
+		params.PaperHeight = 9.84
+	case FormatC4:
+		params.PaperWidth = 9.02
+		params.PaperHeight = 12.76
+	case FormatC5:
+		params.PaperWidth = 6.38
+		params.PaperHeight = 9.02
+	case FormatDL:
+		params.PaperWidth = 4.33
+		params.PaperHeight = 8.66
+	case FormatLetter:
+		params.PaperWidth = 8.5
+		params.PaperHeight = 11.0
+	case FormatLegal:
+		params.PaperWidth = 8.5
+		params.PaperHeight = 14.0
+	case FormatTabloid:
+		params.PaperWidth = 11.0
+		params.PaperHeight = 17.0
+	case FormatCustom, "":
+		// An empty format is accepted for compatibility with older code
+		if g.options.Width > 0 && g.options.Height > 0 {
+			params.PaperWidth = g.options.Width
+			params.PaperHeight = g.options.Height
+		}
 	}
 
 	// Set margins
@@ -158,19 +761,7 @@ func (g *Generator) generatePDF(ctx context.Context) ([]byte, error) {
 		params.FooterTemplate = g.options.FooterTemplate
 	}
 
-	// Generate PDF using the correct chromedp method
-	var pdfData []byte
-	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
-		var err error
-		pdfData, _, err = params.Do(ctx)
-		return err
-	}))
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate PDF: %w", err)
-	}
-
-	return pdfData, nil
+	return params
 }
 
 // Convenience functions for common use cases
@@ -181,6 +772,12 @@ func FromHTML(htmlContent string) ([]byte, error) {
 	return generator.FromHTML(htmlContent)
 }
 
+// FromFile is a convenience function for basic HTML file to PDF conversion
+func FromFile(path string) ([]byte, error) {
+	generator := NewGenerator(DefaultOptions())
+	return generator.FromFile(path)
+}
+
 // FromURL is a convenience function for basic URL to PDF conversion
 func FromURL(url string) ([]byte, error) {
 	generator := NewGenerator(DefaultOptions())
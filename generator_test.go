@@ -0,0 +1,147 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+)
+
+// largeHTML returns an HTML document of more than size bytes, with # and ?
+// characters that data URLs needed escaped, in which the text after the
+// padding starts a second page
+func largeHTML(size int) string {
+	padding := strings.Repeat("data:image/png;base64,iVBORw0KGgo#?%20&amp;", size/41+1)
+	return `<!DOCTYPE html><html><body><p>first page</p>` +
+		`<!-- ` + padding + ` -->` +
+		`<p style="break-before: page">last page #1?</p></body></html>`
+}
+
+func TestReadHTMLLarge(t *testing.T) {
+	html := largeHTML(6 << 20)
+
+	tests := []struct {
+		name    string
+		max     int64
+		wantErr error
+	}{
+		{"no limit", 0, nil},
+		{"limit above", int64(len(html)) + 1, nil},
+		{"limit exactly", int64(len(html)), nil},
+		{"limit below", 5 << 20, ErrInputTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.MaxInputSize = tt.max
+
+			got, err := NewGenerator(opts).readHTML(strings.NewReader(html))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readHTML() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != html {
+				t.Errorf("readHTML() returned %d bytes, want the %d bytes read", len(got), len(html))
+			}
+		})
+	}
+}
+
+// recordingExecutor answers the CDP commands setDocumentContent sends and
+// records the HTML it sets
+type recordingExecutor struct {
+	frameID cdp.FrameID
+	html    string
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, method string, params, res any) error {
+	switch method {
+	case page.CommandGetFrameTree:
+		res.(*page.GetFrameTreeReturns).FrameTree = &page.FrameTree{Frame: &cdp.Frame{ID: e.frameID}}
+	case page.CommandSetDocumentContent:
+		p := params.(*page.SetDocumentContentParams)
+		if p.FrameID != e.frameID {
+			return errors.New("unexpected frame " + string(p.FrameID))
+		}
+		e.html = p.HTML
+	default:
+		return errors.New("unexpected command " + method)
+	}
+	return nil
+}
+
+func TestSetDocumentContentLarge(t *testing.T) {
+	html := largeHTML(6 << 20)
+
+	executor := &recordingExecutor{frameID: "main"}
+	ctx := cdp.WithExecutor(context.Background(), executor)
+	if err := setDocumentContent(html).Do(ctx); err != nil {
+		t.Fatalf("setDocumentContent() error = %v", err)
+	}
+
+	// Sent as is, where a data URL was escaped and truncated around 2MB
+	if executor.html != html {
+		t.Errorf("set %d bytes of HTML, want the %d bytes given", len(executor.html), len(html))
+	}
+}
+
+func TestFromHTMLLarge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	g := NewGenerator(DefaultOptions())
+	defer g.Close()
+
+	pdf, err := g.FromHTML(largeHTML(6 << 20))
+	if errors.Is(err, ErrBrowserStart) {
+		t.Skipf("Chrome is not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("FromHTML() error = %v", err)
+	}
+
+	// A truncated document loses the page after the padding
+	if n, err := PageCount(pdf); err != nil || n != 2 {
+		t.Errorf("PageCount() = %d, %v, want 2", n, err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := template.Must(template.New("invoice").Option("missingkey=error").Parse(`<h1>Invoice {{.Number}}</h1>`))
+
+	tests := []struct {
+		name    string
+		builder *OptionsBuilder
+		want    error
+		message string
+	}{
+		{"no template", WithOptions(), ErrInvalidOptions, "no template set"},
+		{"failing template", WithOptions().Template(tmpl, map[string]any{}), nil, "failed to execute template"},
+		// Chrome is only launched once the template executed
+		{"template", WithOptions().Template(tmpl, map[string]any{"Number": 42}), ErrBrowserStart, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.builder.ChromePath("/nonexistent/chrome")
+			_, err := b.Build().Render()
+			if err == nil {
+				t.Fatal("Render() succeeded without Chrome")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Render() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && errors.Is(err, ErrBrowserStart) {
+				t.Errorf("Render() launched Chrome for a failing template: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Render() error = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Handler serves a PDF generated from the HTML htmlProvider returns for
+// each request, e.g. an invoice rendered from a template. The PDF is shown
+// inline as document.pdf. Errors are answered with a status that reflects
+// them, without details, which go to the generator's Logger.
+func Handler(generator *Generator, htmlProvider func(*http.Request) (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		htmlContent, err := htmlProvider(r)
+		if err != nil {
+			generator.options.logger().Error("failed to provide HTML", "path", r.URL.Path, "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		pdf, err := generator.FromHTMLContext(r.Context(), htmlContent)
+		servePDF(generator, w, r, pdf, err)
+	})
+}
+
+// URLHandler serves a PDF of the page at the url query parameter, e.g.
+// /pdf?url=https://example.com/report. Only http and https URLs are
+// accepted, but any host is, so it belongs behind authentication or a
+// proxy restricting which hosts Chrome can reach.
+func URLHandler(generator *Generator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("url")
+		u, err := url.Parse(target)
+		if target == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			http.Error(w, "url must be an absolute http or https URL", http.StatusBadRequest)
+			return
+		}
+
+		pdf, err := generator.FromURLContext(r.Context(), target)
+		servePDF(generator, w, r, pdf, err)
+	})
+}
+
+// servePDF writes pdf as the response, or logs err and answers with its
+// status
+func servePDF(generator *Generator, w http.ResponseWriter, r *http.Request, pdf []byte, err error) {
+	if err != nil {
+		generator.options.logger().Error("failed to serve PDF", "path", r.URL.Path, "error", err)
+		if r.Context().Err() != nil {
+			// The client is gone, so nobody reads the response
+			return
+		}
+		status := errorStatus(err)
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="document.pdf"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	if r.Method != http.MethodHead {
+		w.Write(pdf)
+	}
+}
+
+// errorStatus returns the HTTP status that reflects a generation error
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrNavigation):
+		return http.StatusBadGateway
+	case errors.Is(err, ErrBrowserStart):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// PDFMiddleware serves the HTML responses of next as PDFs to requests that
+// accept application/pdf, so existing HTML endpoints can serve PDFs as
+// well. Other requests pass through unmodified. Only 2xx text/html responses
+// are converted; others, such as errors and redirects, are sent as next
+// wrote them. Relative links in the HTML resolve against BaseURL.
+func PDFMiddleware(generator *Generator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acceptsPDF(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// next is asked for the HTML to convert
+			inner := r.Clone(r.Context())
+			inner.Header.Set("Accept", "text/html")
+
+			captured := &capturedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(captured, inner)
+
+			if !captured.convertible() {
+				captured.copyTo(w)
+				return
+			}
+
+			pdf, err := generator.FromHTMLContext(r.Context(), captured.body.String())
+			if err == nil {
+				for k, v := range captured.header {
+					w.Header()[k] = v
+				}
+				// They describe the HTML
+				for _, k := range []string{"Content-Length", "Content-Encoding", "ETag", "Last-Modified"} {
+					w.Header().Del(k)
+				}
+				w.Header().Add("Vary", "Accept")
+			}
+			servePDF(generator, w, r, pdf, err)
+		})
+	}
+}
+
+// acceptsPDF reports whether the request's Accept header lists
+// application/pdf
+func acceptsPDF(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		for _, accepted := range strings.Split(value, ",") {
+			mediaType, _, _ := strings.Cut(accepted, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), "application/pdf") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// capturedResponse is a response held back to be converted to a PDF
+type capturedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *capturedResponse) Header() http.Header {
+	return c.header
+}
+
+func (c *capturedResponse) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status, c.wroteHeader = status, true
+	}
+}
+
+func (c *capturedResponse) Write(p []byte) (int, error) {
+	c.WriteHeader(http.StatusOK)
+	return c.body.Write(p)
+}
+
+// convertible reports whether the response is uncompressed HTML with a 2xx
+// status
+func (c *capturedResponse) convertible() bool {
+	if c.status < 200 || c.status >= 300 {
+		return false
+	}
+	if encoding := c.header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return false
+	}
+
+	contentType := c.header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(c.body.Bytes())
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), "text/html")
+}
+
+// copyTo sends the response to w as captured
+func (c *capturedResponse) copyTo(w http.ResponseWriter) {
+	for k, v := range c.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(c.status)
+	w.Write(c.body.Bytes())
+}
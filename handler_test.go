@@ -0,0 +1,217 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// unlaunchable returns a generator whose browser fails to start
+func unlaunchable() *Generator {
+	opts := DefaultOptions()
+	opts.ChromePath = "/nonexistent/chrome"
+	return NewGenerator(opts)
+}
+
+func TestServePDF(t *testing.T) {
+	pdf := []byte("%PDF-1.7 test")
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		t.Run(method, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			servePDF(NewGenerator(DefaultOptions()), rec, httptest.NewRequest(method, "/invoice", nil), pdf, nil)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", rec.Code)
+			}
+			headers := map[string]string{
+				"Content-Type":        "application/pdf",
+				"Content-Disposition": `inline; filename="document.pdf"`,
+				"Content-Length":      fmt.Sprint(len(pdf)),
+			}
+			for name, want := range headers {
+				if got := rec.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+
+			want := pdf
+			if method == http.MethodHead {
+				want = nil
+			}
+			if !bytes.Equal(rec.Body.Bytes(), want) {
+				t.Errorf("body = %q, want %q", rec.Body.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("failed to generate PDF: %w", ErrTimeout), http.StatusGatewayTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{&NavigationError{URL: "https://example.invalid", Cause: errors.New("net::ERR_NAME_NOT_RESOLVED")}, http.StatusBadGateway},
+		{&HTTPError{StatusCode: 404, URL: "https://example.com"}, http.StatusBadGateway},
+		{fmt.Errorf("failed to generate PDF: %w", ErrBrowserStart), http.StatusServiceUnavailable},
+		{ErrInvalidOptions, http.StatusInternalServerError},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := errorStatus(tt.err); got != tt.want {
+			t.Errorf("errorStatus(%q) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	invalid := DefaultOptions()
+	invalid.Timeout = 0
+
+	html := func(*http.Request) (string, error) { return "<p>Hello</p>", nil }
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		target  string
+		status  int
+	}{
+		{"provider error", Handler(unlaunchable(), func(*http.Request) (string, error) {
+			return "", errors.New("invoice not found")
+		}), "/invoice", http.StatusInternalServerError},
+		{"browser fails to start", Handler(unlaunchable(), html), "/invoice", http.StatusServiceUnavailable},
+		{"invalid options", Handler(NewGenerator(invalid), html), "/invoice", http.StatusInternalServerError},
+		{"URL browser fails to start", URLHandler(unlaunchable()), "/pdf?url=" + url.QueryEscape("https://example.com"), http.StatusServiceUnavailable},
+		{"no URL", URLHandler(unlaunchable()), "/pdf", http.StatusBadRequest},
+		{"relative URL", URLHandler(unlaunchable()), "/pdf?url=/report", http.StatusBadRequest},
+		{"file URL", URLHandler(unlaunchable()), "/pdf?url=" + url.QueryEscape("file:///etc/passwd"), http.StatusBadRequest},
+		{"no host", URLHandler(unlaunchable()), "/pdf?url=" + url.QueryEscape("https:///report"), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct == "application/pdf" {
+				t.Errorf("error answered with Content-Type %q", ct)
+			}
+			if disposition := rec.Header().Get("Content-Disposition"); disposition != "" {
+				t.Errorf("error answered with Content-Disposition %q", disposition)
+			}
+		})
+	}
+}
+
+func TestHandlerClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/invoice", nil).WithContext(ctx)
+	Handler(NewGenerator(DefaultOptions()), func(*http.Request) (string, error) {
+		return "<p>Hello</p>", nil
+	}).ServeHTTP(rec, r)
+
+	// Nothing is written for a client that is gone
+	if rec.Body.Len() != 0 || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("wrote %d %q with headers %v", rec.Code, rec.Body, rec.Header())
+	}
+}
+
+func TestPDFMiddlewarePassThrough(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<p>Hello</p>")
+	})
+	handler := PDFMiddleware(unlaunchable())(next)
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		status int
+		body   string
+	}{
+		{"HTML requested", "/", "text/html", http.StatusOK, "<p>Hello</p>"},
+		{"error response", "/missing", "application/pdf", http.StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			r.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.status || rec.Body.String() != tt.body {
+				t.Errorf("response = %d %q, want %d %q", rec.Code, rec.Body, tt.status, tt.body)
+			}
+		})
+	}
+
+	// HTML that is converted fails like the handlers' renders do
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/pdf")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	g := NewGenerator(DefaultOptions())
+	defer g.Close()
+
+	server := httptest.NewServer(Handler(g, func(r *http.Request) (string, error) {
+		return "<h1>Invoice " + r.URL.Query().Get("id") + "</h1>", nil
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/invoice?id=1042")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		t.Skip("Chrome is not available")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q, want application/pdf", ct)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != `inline; filename="document.pdf"` {
+		t.Errorf("Content-Disposition = %q", disposition)
+	}
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if n, err := PageCount(body.Bytes()); err != nil || n != 1 {
+		t.Errorf("PageCount() = %d, %v, want 1 page", n, err)
+	}
+}
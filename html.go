@@ -0,0 +1,47 @@
+package htmlgopdf
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	baseTag    = regexp.MustCompile(`(?i)<base\s[^>]*href`)
+	headTag    = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+	htmlTag    = regexp.MustCompile(`(?i)<html(\s[^>]*)?>`)
+	doctypeTag = regexp.MustCompile(`(?i)<!doctype[^>]*>`)
+)
+
+// withBaseURL adds a <base href> tag pointing at baseURL to the document,
+// so its relative links resolve against it. A <base href> already in the
+// document wins. baseURL is taken to be a directory, so a trailing slash
+// is added when missing.
+func withBaseURL(htmlContent, baseURL string) string {
+	if baseURL == "" || baseTag.MatchString(htmlContent) {
+		return htmlContent
+	}
+	if u, err := url.Parse(baseURL); err == nil && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		if u.RawPath != "" {
+			u.RawPath += "/"
+		}
+		baseURL = u.String()
+	}
+
+	return insertIntoHead(htmlContent, `<base href="`+html.EscapeString(baseURL)+`">`)
+}
+
+// insertIntoHead inserts markup at the start of the document's head. Without
+// a <head> tag it goes after <html> or the doctype, since anything before the
+// doctype would switch the page to quirks mode.
+func insertIntoHead(htmlContent, markup string) string {
+	for _, tag := range []*regexp.Regexp{headTag, htmlTag, doctypeTag} {
+		if loc := tag.FindStringIndex(htmlContent); loc != nil {
+			return htmlContent[:loc[1]] + markup + htmlContent[loc[1]:]
+		}
+	}
+
+	return markup + htmlContent
+}
@@ -0,0 +1,98 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+)
+
+// fetchWithClient performs a paused request with the configured HTTP client
+// and hands the response to Chrome, which then treats it as if it had made
+// the request itself
+func (i *interceptor) fetchWithClient(ctx context.Context, ev *fetch.EventRequestPaused, headers []*fetch.HeaderEntry) {
+	resp, err := i.do(ctx, ev, headers)
+	if err != nil {
+		i.warn("request to %s failed: %v", ev.Request.URL, err)
+		_ = fetch.FailRequest(ev.RequestID, network.ErrorReasonFailed).Do(ctx)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		i.warn("failed to read response from %s: %v", ev.Request.URL, err)
+		_ = fetch.FailRequest(ev.RequestID, network.ErrorReasonFailed).Do(ctx)
+		return
+	}
+
+	var responseHeaders []*fetch.HeaderEntry
+	for name, values := range resp.Header {
+		for _, value := range values {
+			responseHeaders = append(responseHeaders, &fetch.HeaderEntry{Name: name, Value: value})
+		}
+	}
+
+	_ = fetch.FulfillRequest(ev.RequestID, int64(resp.StatusCode)).
+		WithResponseHeaders(responseHeaders).
+		WithResponsePhrase(http.StatusText(resp.StatusCode)).
+		WithBody(base64.StdEncoding.EncodeToString(body)).
+		Do(ctx)
+}
+
+// do sends the paused request through the HTTP client without following
+// redirects, so that Chrome sees and follows them as it would on its own
+func (i *interceptor) do(ctx context.Context, ev *fetch.EventRequestPaused, headers []*fetch.HeaderEntry) (*http.Response, error) {
+	var body io.Reader
+	if ev.Request.HasPostData {
+		var data bytes.Buffer
+		for _, entry := range ev.Request.PostDataEntries {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode request body: %w", err)
+			}
+			data.Write(decoded)
+		}
+		body = &data
+	}
+
+	req, err := http.NewRequestWithContext(ctx, ev.Request.Method, ev.Request.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if headers == nil {
+		for name, value := range ev.Request.Headers {
+			headers = append(headers, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+	for _, h := range headers {
+		// Left to the client's transport, which then also decompresses
+		if strings.EqualFold(h.Name, "Accept-Encoding") {
+			continue
+		}
+		req.Header.Set(h.Name, h.Value)
+	}
+
+	// Chrome adds cookies after the request is paused, so look them up
+	if cookies, err := network.GetCookies().WithURLs([]string{ev.Request.URL}).Do(ctx); err == nil && len(cookies) > 0 {
+		pairs := make([]string, len(cookies))
+		for n, c := range cookies {
+			pairs[n] = c.Name + "=" + c.Value
+		}
+		req.Header.Set("Cookie", strings.Join(pairs, "; "))
+	}
+
+	client := *i.options.HTTPClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return client.Do(req)
+}
@@ -0,0 +1,110 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// srgbProfile builds an ICC v2 display profile for sRGB IEC61966-2.1, the
+// color space Chrome renders in, to be used as the output intent of PDF/A
+// documents
+func srgbProfile() []byte {
+	type tag struct {
+		sig  string
+		data []byte
+	}
+
+	// The three channels share the same curve
+	trc := srgbCurve()
+	tags := []tag{
+		{"desc", iccDescription("sRGB IEC61966-2.1")},
+		{"cprt", iccText("No copyright, use freely")},
+		{"wtpt", iccXYZ(0.9642, 1.0, 0.8249)},
+		{"rXYZ", iccXYZ(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", iccXYZ(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", iccXYZ(0.1431, 0.0606, 0.7141)},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+
+	var table, data bytes.Buffer
+	offset := 128 + 4 + 12*len(tags)
+	offsets := make(map[*byte]int)
+	binary.Write(&table, binary.BigEndian, uint32(len(tags)))
+	for _, t := range tags {
+		// Tags with the same data point at a single copy of it
+		at, ok := offsets[&t.data[0]]
+		if !ok {
+			at = offset + data.Len()
+			offsets[&t.data[0]] = at
+			data.Write(t.data)
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+		}
+		table.WriteString(t.sig)
+		binary.Write(&table, binary.BigEndian, uint32(at))
+		binary.Write(&table, binary.BigEndian, uint32(len(t.data)))
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(128+table.Len()+data.Len()))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000) // Version 2.1
+	copy(header[12:], "mntrRGB XYZ ")
+	binary.BigEndian.PutUint16(header[24:], 2000) // Creation date, 2000-01-01
+	binary.BigEndian.PutUint16(header[26:], 1)
+	binary.BigEndian.PutUint16(header[28:], 1)
+	copy(header[36:], "acsp")
+	copy(header[68:], iccXYZ(0.9642, 1.0, 0.8249)[8:]) // D50 illuminant
+
+	profile := append(header, table.Bytes()...)
+	return append(profile, data.Bytes()...)
+}
+
+// srgbCurve returns the sRGB transfer function as a curveType table
+func srgbCurve() []byte {
+	const entries = 1024
+
+	var buf bytes.Buffer
+	buf.WriteString("curv\x00\x00\x00\x00")
+	binary.Write(&buf, binary.BigEndian, uint32(entries))
+	for i := 0; i < entries; i++ {
+		v := float64(i) / (entries - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		binary.Write(&buf, binary.BigEndian, uint16(math.Round(v*0xFFFF)))
+	}
+
+	return buf.Bytes()
+}
+
+// iccXYZ returns an XYZType holding a single color
+func iccXYZ(x, y, z float64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("XYZ \x00\x00\x00\x00")
+	for _, v := range []float64{x, y, z} {
+		binary.Write(&buf, binary.BigEndian, int32(math.Round(v*65536)))
+	}
+	return buf.Bytes()
+}
+
+// iccText returns a textType holding s
+func iccText(s string) []byte {
+	return []byte("text\x00\x00\x00\x00" + s + "\x00")
+}
+
+// iccDescription returns a textDescriptionType with an ASCII description
+// and no Unicode or ScriptCode ones
+func iccDescription(s string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("desc\x00\x00\x00\x00")
+	binary.Write(&buf, binary.BigEndian, uint32(len(s)+1))
+	buf.WriteString(s + "\x00")
+	buf.Write(make([]byte, 4+4+2+1+67))
+	return buf.Bytes()
+}
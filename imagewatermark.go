@@ -0,0 +1,184 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// ImageWatermarkOptions describes an image, such as a logo, drawn on every
+// page of the PDF
+type ImageWatermarkOptions struct {
+	PNG         []byte  `json:"png"`
+	X           float64 `json:"x,omitempty"`           // Points from the left edge of the page
+	Y           float64 `json:"y,omitempty"`           // Points from the bottom edge of the page
+	Width       float64 `json:"width"`                 // Width in points
+	Height      float64 `json:"height,omitempty"`      // Height in points, 0 to keep the image's aspect ratio
+	Opacity     float64 `json:"opacity,omitempty"`     // From 0 to 1, 0.3 when zero
+	BottomRight bool    `json:"bottomRight,omitempty"` // Place the image in the bottom-right corner of each page instead of at X and Y
+}
+
+// imageWatermarkMargin is the distance from the edges of the page of an
+// image placed in a corner, half an inch
+const imageWatermarkMargin = 36
+
+// Resource names the image watermark is drawn with
+const (
+	watermarkImage      = "HtmlgopdfWatermarkImage"
+	watermarkImageState = "HtmlgopdfWatermarkImageGS"
+)
+
+// validate checks that the image is a PNG and has a size
+func (w *ImageWatermarkOptions) validate() error {
+	if _, err := png.DecodeConfig(bytes.NewReader(w.PNG)); err != nil {
+		return fmt.Errorf("invalid watermark image: %w", err)
+	}
+	if w.Width <= 0 || w.Height < 0 {
+		return fmt.Errorf("invalid watermark image size %gx%g", w.Width, w.Height)
+	}
+	if w.Opacity < 0 || w.Opacity > 1 {
+		return fmt.Errorf("invalid watermark opacity %g: must be between 0 and 1", w.Opacity)
+	}
+	return nil
+}
+
+// applyImageWatermark draws the image watermark set in opts over every
+// page of data
+func applyImageWatermark(data []byte, opts *PDFOptions) ([]byte, error) {
+	w := opts.ImageWatermark
+	if w == nil {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply image watermark: %w", err)
+	}
+
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply image watermark: %w", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(w.PNG))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode watermark image: %w", err)
+	}
+	imageRef, err := addImage(doc, img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed watermark image: %w", err)
+	}
+
+	opacity := w.Opacity
+	if opacity == 0 {
+		opacity = 0.3
+	}
+	stateRef := doc.add(pdfDict{
+		"Type": pdfName("ExtGState"),
+		"ca":   opacity,
+		"CA":   opacity,
+	})
+	save := doc.add(&pdfStream{Dict: pdfDict{}, Data: []byte("q\n")})
+
+	width, height := w.Width, w.Height
+	if height == 0 {
+		bounds := img.Bounds()
+		height = width * float64(bounds.Dy()) / float64(bounds.Dx())
+	}
+
+	for _, ref := range pages {
+		page := doc.dict(ref)
+
+		x, y := w.X, w.Y
+		if w.BottomRight {
+			box, _ := doc.resolve(page["MediaBox"]).(pdfArray)
+			if len(box) == 4 {
+				x = pdfNumber(doc.resolve(box[2])) - imageWatermarkMargin - width
+				y = pdfNumber(doc.resolve(box[1])) + imageWatermarkMargin
+			}
+		}
+
+		content := fmt.Sprintf("Q\nq\n/%s gs\n%s 0 0 %s %s %s cm\n/%s Do\nQ\n", watermarkImageState,
+			formatReal(width), formatReal(height), formatReal(x), formatReal(y), watermarkImage)
+		watermark := doc.add(&pdfStream{Dict: pdfDict{}, Data: []byte(content)})
+
+		drawOver(doc, page, save, watermark)
+
+		page["Resources"] = withResource(doc, page["Resources"], "XObject", watermarkImage, imageRef)
+		page["Resources"] = withResource(doc, page["Resources"], "ExtGState", watermarkImageState, stateRef)
+	}
+
+	return doc.bytes(), nil
+}
+
+// addImage embeds img as an RGB image XObject, with its alpha channel as a
+// soft mask when it isn't opaque
+func addImage(doc *pdfDocument, img image.Image) (pdfRef, error) {
+	bounds := img.Bounds()
+	rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	alpha := make([]byte, 0, bounds.Dx()*bounds.Dy())
+	opaque := true
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			// Undo the premultiplication of the color by alpha
+			if a > 0 && a < 0xFFFF {
+				r, g, b = r*0xFFFF/a, g*0xFFFF/a, b*0xFFFF/a
+			}
+			rgb = append(rgb, byte(r>>8), byte(g>>8), byte(b>>8))
+			alpha = append(alpha, byte(a>>8))
+			if a != 0xFFFF {
+				opaque = false
+			}
+		}
+	}
+
+	dict := pdfDict{
+		"Type":             pdfName("XObject"),
+		"Subtype":          pdfName("Image"),
+		"Width":            int64(bounds.Dx()),
+		"Height":           int64(bounds.Dy()),
+		"ColorSpace":       pdfName("DeviceRGB"),
+		"BitsPerComponent": int64(8),
+		"Filter":           pdfName("FlateDecode"),
+	}
+
+	if !opaque {
+		mask, err := deflate(alpha)
+		if err != nil {
+			return pdfRef{}, err
+		}
+		dict["SMask"] = doc.add(&pdfStream{Dict: pdfDict{
+			"Type":             pdfName("XObject"),
+			"Subtype":          pdfName("Image"),
+			"Width":            int64(bounds.Dx()),
+			"Height":           int64(bounds.Dy()),
+			"ColorSpace":       pdfName("DeviceGray"),
+			"BitsPerComponent": int64(8),
+			"Filter":           pdfName("FlateDecode"),
+		}, Data: mask})
+	}
+
+	data, err := deflate(rgb)
+	if err != nil {
+		return pdfRef{}, err
+	}
+
+	return doc.add(&pdfStream{Dict: dict, Data: data}), nil
+}
+
+// deflate compresses data for the FlateDecode filter
+func deflate(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,244 @@
+package htmlgopdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/chromedp/cdproto/css"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// runScripts evaluates the configured JavaScript snippets in order, waiting
+// for the ones that return a promise
+func (g *Generator) runScripts() chromedp.Action {
+	var actions chromedp.Tasks
+
+	for n, script := range g.options.JSSnippets {
+		actions = append(actions, chromedp.ActionFunc(func(ctx context.Context) error {
+			err := chromedp.Evaluate(script, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("script %d failed: %w", n, err)
+			}
+			return nil
+		}))
+	}
+
+	return actions
+}
+
+// bypassCSP turns off the page's Content-Security-Policy when there are
+// PreloadScriptURLs, which it would otherwise likely block. It has to be
+// set before navigating.
+func (g *Generator) bypassCSP() chromedp.Action {
+	if len(g.options.PreloadScriptURLs) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := page.SetBypassCSP(true).Do(ctx); err != nil {
+			return fmt.Errorf("failed to bypass Content-Security-Policy: %w", err)
+		}
+		return nil
+	})
+}
+
+// preloadScripts adds a <script> element for each PreloadScriptURLs and
+// then evaluates each PreloadScripts, in order and waiting for each, so
+// that they run before the wait conditions
+func (g *Generator) preloadScripts() chromedp.Action {
+	var actions chromedp.Tasks
+
+	for _, url := range g.options.PreloadScriptURLs {
+		// JSON encoding makes the URL a valid JavaScript string literal
+		literal, _ := json.Marshal(url)
+
+		script := `new Promise((resolve, reject) => {
+			const script = document.createElement("script");
+			script.src = ` + string(literal) + `;
+			script.onload = () => resolve();
+			script.onerror = () => reject(new Error("failed to load " + script.src));
+			(document.head || document.documentElement).appendChild(script);
+		})`
+
+		actions = append(actions, chromedp.ActionFunc(func(ctx context.Context) error {
+			err := chromedp.Evaluate(script, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("preload script %s failed: %w", url, err)
+			}
+			return nil
+		}))
+	}
+
+	for n, script := range g.options.PreloadScripts {
+		actions = append(actions, chromedp.ActionFunc(func(ctx context.Context) error {
+			err := chromedp.Evaluate(script, nil, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("preload script %d failed: %w", n, err)
+			}
+			return nil
+		}))
+	}
+
+	return actions
+}
+
+// evalBeforePrint evaluates the EvalBeforePrint expressions in order right
+// before printing, waiting for the ones that return a promise. Their
+// results are logged and, when the render fills in res, kept as JSON.
+func (g *Generator) evalBeforePrint(log *slog.Logger, res *Result) chromedp.Action {
+	expressions := g.options.EvalBeforePrint
+	if len(expressions) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		results := make([]json.RawMessage, 0, len(expressions))
+		for n, expression := range expressions {
+			var result []byte
+			err := chromedp.Evaluate(expression, &result, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("eval %d failed: %w", n, err)
+			}
+
+			if result == nil {
+				// undefined
+				result = []byte("null")
+			}
+			log.Debug("eval finished", "index", n, "result", string(result))
+			results = append(results, result)
+		}
+
+		if res != nil {
+			res.EvalResults = results
+		}
+		return nil
+	})
+}
+
+// injectStyles adds the configured CSS snippets to the document, in order,
+// followed by the rules hiding HideSelectors, as one stylesheet created
+// through the CSS domain. Unlike a <style> element, it isn't subject to the
+// page's Content-Security-Policy.
+func (g *Generator) injectStyles() chromedp.Action {
+	if len(g.options.CSSSnippets) == 0 && len(g.options.HideSelectors) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	text := strings.Join(g.options.CSSSnippets, "\n")
+	for _, selector := range g.options.HideSelectors {
+		text += "\n" + selector + " { display: none !important; }"
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to inject CSS: %w", err)
+		}
+
+		id, err := css.CreateStyleSheet(tree.Frame.ID).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to inject CSS: %w", err)
+		}
+
+		if _, err := css.SetStyleSheetText(id, text).Do(ctx); err != nil {
+			return fmt.Errorf("failed to inject CSS: %w", err)
+		}
+		return nil
+	})
+}
+
+// removeElements removes the elements matching RemoveSelectors from the
+// DOM, reporting how many each selector removed to the logger and res.
+// A selector that removed nothing is reported to OnWarning, or fails the
+// render with StrictRemoveSelectors.
+func (g *Generator) removeElements(log *slog.Logger, res *Result) chromedp.Action {
+	selectors := g.options.RemoveSelectors
+	if len(selectors) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	// JSON encoding makes the selectors a valid JavaScript array literal
+	literal, _ := json.Marshal(selectors)
+	script := `(` + string(literal) + `).map(s => {
+		const elements = document.querySelectorAll(s);
+		elements.forEach(e => e.remove());
+		return elements.length;
+	})`
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var counts []int
+		if err := chromedp.Evaluate(script, &counts).Do(ctx); err != nil {
+			return fmt.Errorf("failed to remove elements: %w", err)
+		}
+
+		removed := make(map[string]int, len(selectors))
+		for i, count := range counts {
+			removed[selectors[i]] += count
+			log.Debug("removed elements", "selector", selectors[i], "count", count)
+		}
+		if res != nil {
+			res.Removed = removed
+		}
+
+		for _, selector := range selectors {
+			if removed[selector] > 0 {
+				continue
+			}
+			if g.options.StrictRemoveSelectors {
+				return fmt.Errorf("remove selector %q matched nothing", selector)
+			}
+			g.warn("remove selector %q matched nothing", selector)
+		}
+		return nil
+	})
+}
+
+// checkHidden warns about HideSelectors that match nothing right before
+// printing, which is often a selector gone stale after a redesign
+func (g *Generator) checkHidden() chromedp.Action {
+	selectors := g.options.HideSelectors
+	if len(selectors) == 0 {
+		return chromedp.Tasks{}
+	}
+
+	// JSON encoding makes the selectors a valid JavaScript array literal
+	literal, _ := json.Marshal(selectors)
+	script := `(` + string(literal) + `).map(s => {
+		try {
+			return document.querySelectorAll(s).length;
+		} catch (e) {
+			return -1;
+		}
+	})`
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var counts []int
+		if err := chromedp.Evaluate(script, &counts).Do(ctx); err != nil {
+			g.warn("failed to check hidden selectors: %v", err)
+			return nil
+		}
+
+		for i, count := range counts {
+			switch {
+			case count < 0:
+				g.warn("hide selector %q is invalid", selectors[i])
+			case count == 0:
+				g.warn("hide selector %q matched nothing", selectors[i])
+			}
+		}
+		return nil
+	})
+}
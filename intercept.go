@@ -0,0 +1,481 @@
+package htmlgopdf
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrDocumentBlocked is returned when StrictNetworkPolicy is set and the
+// document being rendered is denied by AllowedHosts or BlockedURLPatterns
+var ErrDocumentBlocked = errors.New("document blocked by network policy")
+
+// RedirectError is returned when the document is redirected in a way that
+// MaxRedirects or SameOriginRedirectsOnly refuse
+type RedirectError struct {
+	Reason string   // Which rule the redirect broke
+	URL    string   // Where the refused redirect pointed, which was not loaded
+	Chain  []string // URLs the document was loaded from until then, starting with the requested one
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("redirect to %s refused: %s (from %s)", e.URL, e.Reason, strings.Join(e.Chain, " -> "))
+}
+
+// Is makes errors.Is(err, ErrNavigation) report true
+func (e *RedirectError) Is(target error) bool {
+	return target == ErrNavigation
+}
+
+// resourceTypes are the resource types BlockResourceTypes accepts
+var resourceTypes = []network.ResourceType{
+	network.ResourceTypeDocument,
+	network.ResourceTypeStylesheet,
+	network.ResourceTypeImage,
+	network.ResourceTypeMedia,
+	network.ResourceTypeFont,
+	network.ResourceTypeScript,
+	network.ResourceTypeTextTrack,
+	network.ResourceTypeXHR,
+	network.ResourceTypeFetch,
+	network.ResourceTypePrefetch,
+	network.ResourceTypeEventSource,
+	network.ResourceTypeManifest,
+	network.ResourceTypePing,
+	network.ResourceTypeOther,
+}
+
+// interceptor answers Fetch domain events for a single render
+type interceptor struct {
+	options *PDFOptions
+	warn    func(format string, args ...any)
+
+	// Requests whose challenge was already answered, so that rejected
+	// credentials are not retried forever
+	answered sync.Map
+
+	mu        sync.Mutex
+	documents map[string]bool              // Documents the generator itself navigates to
+	origins   []string                     // Servers of assets provided by the caller
+	allowed   map[fetch.RequestID][]string // Document requests let through, so their redirects are too, with the URLs that led to them
+	sameSite  map[string]bool              // Origins of the document, which get the extra headers
+
+	blockedDocument string         // URL of the document, when the network policy blocked it
+	refusedRedirect *RedirectError // Redirect of the document that broke the redirect policy
+	post            *postRequest   // Body the document is requested with, if it is POSTed
+	enabled         bool
+}
+
+// interceptorKey is the context key of the render's interceptor
+type interceptorKey struct{}
+
+// newInterceptor creates the interceptor for a single render
+func (g *Generator) newInterceptor() *interceptor {
+	i := &interceptor{
+		options:   g.options,
+		warn:      g.warn,
+		documents: make(map[string]bool),
+		allowed:   make(map[fetch.RequestID][]string),
+		sameSite:  make(map[string]bool),
+	}
+
+	// HTML content has no origin of its own, its links resolve against BaseURL
+	if g.options.BaseURL != "" {
+		i.sameSite[originOf(g.options.BaseURL)] = true
+	}
+
+	return i
+}
+
+// attach makes the interceptor reachable from actions run with the
+// returned context, such as navigateTo
+func (i *interceptor) attach(ctx context.Context) context.Context {
+	return context.WithValue(ctx, interceptorKey{}, i)
+}
+
+// navigateTo navigates the tab to url, letting the interceptor know that
+// it is the document being rendered
+func navigateTo(url string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if i, ok := ctx.Value(interceptorKey{}).(*interceptor); ok {
+			i.mu.Lock()
+			i.documents[documentKey(url)] = true
+			i.sameSite[originOf(url)] = true
+			i.mu.Unlock()
+		}
+		if err := chromedp.Navigate(url).Do(ctx); err != nil {
+			return &NavigationError{URL: url, Cause: err}
+		}
+		return nil
+	})
+}
+
+// allowOrigin lets the page load anything from base, which serves assets
+// the caller provided
+func allowOrigin(base string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if i, ok := ctx.Value(interceptorKey{}).(*interceptor); ok {
+			i.mu.Lock()
+			i.origins = append(i.origins, base+"/")
+			i.mu.Unlock()
+		}
+		return nil
+	})
+}
+
+// enable turns on request interception for the tab when one of the options
+// needs it. Handlers stop receiving events once ctx is done.
+func (i *interceptor) enable() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		if i.enabled || !i.needed() {
+			return nil
+		}
+		i.enabled = true
+
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			// Handlers send CDP commands, which must not happen on the
+			// listener goroutine
+			switch ev := ev.(type) {
+			case *fetch.EventRequestPaused:
+				go i.handleRequest(ctx, ev)
+			case *fetch.EventAuthRequired:
+				go i.handleAuth(ctx, ev)
+			}
+		})
+
+		if i.options.DisableNetwork {
+			// WebSockets don't go through the Fetch domain
+			if err := network.SetBlockedURLs([]string{"ws://*", "wss://*"}).Do(ctx); err != nil {
+				return err
+			}
+		}
+
+		return fetch.Enable().WithHandleAuthRequests(true).Do(ctx)
+	})
+}
+
+// needed reports whether any option requires request interception
+func (i *interceptor) needed() bool {
+	i.mu.Lock()
+	post := i.post
+	i.mu.Unlock()
+
+	user, _ := proxyCredentials(i.options.ProxyServer)
+	return post != nil || user != "" || i.options.BasicAuthUsername != "" || i.options.DisableNetwork ||
+		len(i.options.AllowedHosts) > 0 || len(i.options.BlockedURLPatterns) > 0 ||
+		len(i.options.BlockResourceTypes) > 0 || len(i.options.Headers) > 0 ||
+		i.options.HTTPClient != nil || i.options.MaxRedirects != 0 || i.options.SameOriginRedirectsOnly
+}
+
+// handleRequest lets a paused request through, unless the options block it
+func (i *interceptor) handleRequest(ctx context.Context, ev *fetch.EventRequestPaused) {
+	if i.refusedRedirectOf(ev) {
+		// Failing the request fails the navigation right away
+		_ = fetch.FailRequest(ev.RequestID, network.ErrorReasonAborted).Do(ctx)
+		return
+	}
+
+	if i.blocked(ev) {
+		if i.options.OnBlockedRequest != nil {
+			i.options.OnBlockedRequest(ev.Request.URL)
+		}
+		_ = fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient).Do(ctx)
+		return
+	}
+
+	headers := i.headers(ev)
+	post := i.postFor(ev)
+	if post != nil {
+		headers = post.rewrite(ev, headers)
+	}
+
+	if i.options.HTTPClient != nil && !i.fromOrigins(ev.Request.URL) &&
+		(strings.HasPrefix(ev.Request.URL, "http:") || strings.HasPrefix(ev.Request.URL, "https:")) {
+		i.fetchWithClient(ctx, ev, headers)
+		return
+	}
+
+	continueRequest := fetch.ContinueRequest(ev.RequestID)
+	if headers != nil {
+		continueRequest = continueRequest.WithHeaders(headers)
+	}
+	if post != nil {
+		continueRequest = continueRequest.WithMethod(http.MethodPost).
+			WithPostData(base64.StdEncoding.EncodeToString(post.body))
+	}
+	_ = continueRequest.Do(ctx)
+}
+
+// fromOrigins reports whether rawURL is served by one of the asset servers
+func (i *interceptor) fromOrigins(rawURL string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, origin := range i.origins {
+		if strings.HasPrefix(rawURL, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// headers returns the request's headers with the extra headers added, or
+// nil when the request is not same-origin with the document
+func (i *interceptor) headers(ev *fetch.EventRequestPaused) []*fetch.HeaderEntry {
+	if len(i.options.Headers) == 0 {
+		return nil
+	}
+
+	if !i.isSameSite(ev.Request.URL) {
+		return nil
+	}
+
+	// Overriding replaces all headers, so the request's own are kept
+	// unless an extra header of the same name replaces them
+	headers := make([]*fetch.HeaderEntry, 0, len(ev.Request.Headers)+len(i.options.Headers))
+	for name, value := range ev.Request.Headers {
+		if !hasHeader(i.options.Headers, name) {
+			headers = append(headers, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+	for name, value := range i.options.Headers {
+		headers = append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+	}
+
+	return headers
+}
+
+// isSameSite reports whether rawURL has one of the document's origins
+func (i *interceptor) isSameSite(rawURL string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.sameSite[originOf(rawURL)]
+}
+
+// hasHeader reports whether headers has name, in any case
+func hasHeader(headers map[string]string, name string) bool {
+	for h := range headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// originOf returns the scheme and host of rawURL
+func originOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// blocked reports whether the request breaks the network policy. The
+// document being rendered is never blocked by type, and only subject to the
+// allow and block lists in strict mode.
+func (i *interceptor) blocked(ev *fetch.EventRequestPaused) bool {
+	if i.fromOrigins(ev.Request.URL) {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	chain, redirected := i.redirectChain(ev)
+	if ev.ResourceType == network.ResourceTypeDocument && (i.documents[documentKey(ev.Request.URL)] || redirected) {
+		if i.options.StrictNetworkPolicy && !i.permitted(ev.Request.URL) {
+			i.blockedDocument = ev.Request.URL
+			return true
+		}
+		i.allowed[ev.RequestID] = append(chain[:len(chain):len(chain)], ev.Request.URL)
+		// A redirect moves the document, and its headers, to the new origin
+		i.sameSite[originOf(ev.Request.URL)] = true
+		return false
+	}
+
+	return i.options.DisableNetwork || i.blockedType(ev.ResourceType) || !i.permitted(ev.Request.URL)
+}
+
+// redirectChain returns the URLs that led to the request when it is a
+// redirect of a document that was let through. Callers hold i.mu.
+func (i *interceptor) redirectChain(ev *fetch.EventRequestPaused) ([]string, bool) {
+	if ev.ResourceType != network.ResourceTypeDocument || ev.RedirectedRequestID == "" {
+		return nil, false
+	}
+	chain, ok := i.allowed[ev.RedirectedRequestID]
+	return chain, ok
+}
+
+// refusedRedirectOf reports whether the request is a redirect of the
+// document that MaxRedirects or SameOriginRedirectsOnly refuse
+func (i *interceptor) refusedRedirectOf(ev *fetch.EventRequestPaused) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	chain, redirected := i.redirectChain(ev)
+	if !redirected {
+		return false
+	}
+
+	var reason string
+	switch {
+	case i.options.MaxRedirects < 0 || (i.options.MaxRedirects > 0 && len(chain) > i.options.MaxRedirects):
+		reason = fmt.Sprintf("more than %d redirects", max(i.options.MaxRedirects, 0))
+	case i.options.SameOriginRedirectsOnly && originOf(ev.Request.URL) != originOf(chain[len(chain)-1]):
+		reason = "cross-origin redirect"
+	default:
+		return false
+	}
+
+	i.refusedRedirect = &RedirectError{
+		Reason: reason,
+		URL:    ev.Request.URL,
+		Chain:  append([]string(nil), chain...),
+	}
+	return true
+}
+
+// blockedType reports whether resources of type t are not to be loaded
+func (i *interceptor) blockedType(t network.ResourceType) bool {
+	for _, blocked := range i.options.BlockResourceTypes {
+		if strings.EqualFold(blocked, string(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// permitted reports whether the allow and block lists let rawURL through
+func (i *interceptor) permitted(rawURL string) bool {
+	for _, pattern := range i.options.BlockedURLPatterns {
+		if matchWildcard(pattern, rawURL) {
+			return false
+		}
+	}
+
+	if len(i.options.AllowedHosts) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, host := range i.options.AllowedHosts {
+		if matchWildcard(strings.ToLower(host), strings.ToLower(u.Hostname())) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// documentError returns ErrDocumentBlocked when the network policy
+// blocked the document being rendered, or a RedirectError when the redirect
+// policy stopped it
+func (i *interceptor) documentError() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.refusedRedirect != nil {
+		return i.refusedRedirect
+	}
+	if i.blockedDocument == "" {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDocumentBlocked, i.blockedDocument)
+}
+
+// matchWildcard reports whether s matches pattern, in which * stands for
+// any run of characters, including none
+func matchWildcard(pattern, s string) bool {
+	// Position after the last * seen, and where its match would resume
+	star, resume := -1, 0
+
+	p, j := 0, 0
+	for j < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, resume = p+1, j
+			p++
+		case p < len(pattern) && pattern[p] == s[j]:
+			p++
+			j++
+		case star >= 0:
+			// Let the last * swallow one more character
+			resume++
+			p, j = star, resume
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// documentKey normalizes a document URL the way Chrome reports the request
+// for it, e.g. with the fragment dropped
+func documentKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	u.Fragment, u.RawFragment = "", ""
+	if u.Path == "" && u.Host != "" {
+		u.Path = "/"
+	}
+
+	return u.String()
+}
+
+// handleAuth answers authentication challenges with configured credentials
+func (i *interceptor) handleAuth(ctx context.Context, ev *fetch.EventAuthRequired) {
+	_ = fetch.ContinueWithAuth(ev.RequestID, i.authResponse(ev)).Do(ctx)
+}
+
+// authResponse returns the answer to an authentication challenge. The
+// BasicAuth credentials only go to the document's origins, never to third
+// parties the page loads from.
+func (i *interceptor) authResponse(ev *fetch.EventAuthRequired) *fetch.AuthChallengeResponse {
+	response := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseDefault}
+
+	var user, password string
+	if ev.AuthChallenge.Source == fetch.AuthChallengeSourceProxy {
+		user, password = proxyCredentials(i.options.ProxyServer)
+	} else {
+		user, password = i.options.BasicAuthUsername, i.options.BasicAuthPassword
+		if user != "" && !i.isSameSite(ev.Request.URL) {
+			// Let the 401 through
+			return &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+		}
+	}
+
+	if user != "" {
+		if _, retry := i.answered.LoadOrStore(ev.RequestID, true); retry {
+			// The credentials were rejected, let the 401/407 through
+			response = &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+		} else {
+			response = &fetch.AuthChallengeResponse{
+				Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+				Username: user,
+				Password: password,
+			}
+		}
+	}
+
+	return response
+}
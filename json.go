@@ -0,0 +1,106 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// pdfOptionsJSON has the fields of PDFOptions without its JSON methods
+type pdfOptionsJSON PDFOptions
+
+// optionsJSON is PDFOptions with its durations in milliseconds. They
+// shadow the fields of the same JSON names in pdfOptionsJSON.
+type optionsJSON struct {
+	*pdfOptionsJSON
+
+	WaitTime               int64 `json:"waitTime"`
+	WaitForFunctionPoll    int64 `json:"waitForFunctionPoll,omitempty"`
+	WaitForFunctionTimeout int64 `json:"waitForFunctionTimeout,omitempty"`
+	WaitForImagesTimeout   int64 `json:"waitForImagesTimeout,omitempty"`
+	WaitForFontsTimeout    int64 `json:"waitForFontsTimeout,omitempty"`
+	NetworkIdleTimeout     int64 `json:"networkIdleTimeout,omitempty"`
+	NetworkIdle            int64 `json:"networkIdle,omitempty"`
+	NetworkIdleMaxWait     int64 `json:"networkIdleMaxWait,omitempty"`
+	Timeout                int64 `json:"timeout"`
+	RetryBackoff           int64 `json:"retryBackoff,omitempty"`
+}
+
+// MarshalJSON encodes the options with their durations as milliseconds.
+// Callbacks, the logger, the HTTP client and chromedp actions are left
+// out. Fields DefaultOptions sets are encoded even when zero, so that
+// LoadOptionsFromJSON gives them back. It has a value receiver so that
+// PDFOptions values encode the same as pointers.
+func (o PDFOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(optionsJSON{
+		pdfOptionsJSON:         (*pdfOptionsJSON)(&o),
+		WaitTime:               o.WaitTime.Milliseconds(),
+		WaitForFunctionPoll:    o.WaitForFunctionPoll.Milliseconds(),
+		WaitForFunctionTimeout: o.WaitForFunctionTimeout.Milliseconds(),
+		WaitForImagesTimeout:   o.WaitForImagesTimeout.Milliseconds(),
+		WaitForFontsTimeout:    o.WaitForFontsTimeout.Milliseconds(),
+		NetworkIdleTimeout:     o.NetworkIdleTimeout.Milliseconds(),
+		NetworkIdle:            o.NetworkIdle.Milliseconds(),
+		NetworkIdleMaxWait:     o.NetworkIdleMaxWait.Milliseconds(),
+		Timeout:                o.Timeout.Milliseconds(),
+		RetryBackoff:           o.RetryBackoff.Milliseconds(),
+	})
+}
+
+// UnmarshalJSON decodes options encoded by MarshalJSON, with durations as
+// milliseconds. Fields missing from data keep their values.
+func (o *PDFOptions) UnmarshalJSON(data []byte) error {
+	return o.decode(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// decode reads the options from dec, with durations as milliseconds
+func (o *PDFOptions) decode(dec *json.Decoder) error {
+	aux := optionsJSON{
+		pdfOptionsJSON:         (*pdfOptionsJSON)(o),
+		WaitTime:               o.WaitTime.Milliseconds(),
+		WaitForFunctionPoll:    o.WaitForFunctionPoll.Milliseconds(),
+		WaitForFunctionTimeout: o.WaitForFunctionTimeout.Milliseconds(),
+		WaitForImagesTimeout:   o.WaitForImagesTimeout.Milliseconds(),
+		WaitForFontsTimeout:    o.WaitForFontsTimeout.Milliseconds(),
+		NetworkIdleTimeout:     o.NetworkIdleTimeout.Milliseconds(),
+		NetworkIdle:            o.NetworkIdle.Milliseconds(),
+		NetworkIdleMaxWait:     o.NetworkIdleMaxWait.Milliseconds(),
+		Timeout:                o.Timeout.Milliseconds(),
+		RetryBackoff:           o.RetryBackoff.Milliseconds(),
+	}
+	if err := dec.Decode(&aux); err != nil {
+		return err
+	}
+
+	o.WaitTime = time.Duration(aux.WaitTime) * time.Millisecond
+	o.WaitForFunctionPoll = time.Duration(aux.WaitForFunctionPoll) * time.Millisecond
+	o.WaitForFunctionTimeout = time.Duration(aux.WaitForFunctionTimeout) * time.Millisecond
+	o.WaitForImagesTimeout = time.Duration(aux.WaitForImagesTimeout) * time.Millisecond
+	o.WaitForFontsTimeout = time.Duration(aux.WaitForFontsTimeout) * time.Millisecond
+	o.NetworkIdleTimeout = time.Duration(aux.NetworkIdleTimeout) * time.Millisecond
+	o.NetworkIdle = time.Duration(aux.NetworkIdle) * time.Millisecond
+	o.NetworkIdleMaxWait = time.Duration(aux.NetworkIdleMaxWait) * time.Millisecond
+	o.Timeout = time.Duration(aux.Timeout) * time.Millisecond
+	o.RetryBackoff = time.Duration(aux.RetryBackoff) * time.Millisecond
+	return nil
+}
+
+// LoadOptionsFromJSON reads options from a JSON document, e.g. a config
+// file, on top of DefaultOptions. Unknown fields are rejected, so a typo
+// doesn't go unnoticed, and the options are validated.
+func LoadOptionsFromJSON(r io.Reader) (*PDFOptions, error) {
+	opts := DefaultOptions()
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := opts.decode(dec); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode JSON: %w", ErrInvalidOptions, err)
+	}
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
@@ -0,0 +1,49 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Format = FormatLetter
+	opts.MarginTop, opts.MarginBottom, opts.MarginLeft, opts.MarginRight = 0, 0, 0, 0
+	opts.PrintBackground = false
+	opts.WaitTime = 0
+	opts.Scale = 0
+	opts.Landscape = true
+	opts.WaitForSelector = "#ready"
+	opts.NetworkIdle = 750 * time.Millisecond
+	opts.Keywords = []string{"report", "2024"}
+	opts.Title = "Report"
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	loaded, err := LoadOptionsFromJSON(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadOptionsFromJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, opts) {
+		t.Errorf("LoadOptionsFromJSON(%s) = %+v, want %+v", data, loaded, opts)
+	}
+}
+
+func TestLoadOptionsFromJSONDefaults(t *testing.T) {
+	loaded, err := LoadOptionsFromJSON(bytes.NewReader([]byte(`{"landscape":true}`)))
+	if err != nil {
+		t.Fatalf("LoadOptionsFromJSON() error = %v", err)
+	}
+
+	want := DefaultOptions()
+	want.Landscape = true
+	if !reflect.DeepEqual(loaded, want) {
+		t.Errorf("LoadOptionsFromJSON() = %+v, want %+v", loaded, want)
+	}
+}
@@ -0,0 +1,71 @@
+package htmlgopdf
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// discardLogger is used when no Logger is set, so that nothing is logged
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// logger returns the configured logger, or one that discards everything
+func (o *PDFOptions) logger() *slog.Logger {
+	if o.Logger == nil {
+		return discardLogger
+	}
+	return o.Logger
+}
+
+// timed runs action and logs msg at debug level with how long it took,
+// which is also stored in d when it isn't nil
+func timed(log *slog.Logger, msg string, d *time.Duration, action chromedp.Action) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		start := time.Now()
+		if err := action.Do(ctx); err != nil {
+			return err
+		}
+		elapsed := time.Since(start)
+		if d != nil {
+			*d = elapsed
+		}
+		log.Debug(msg, "duration", elapsed)
+		return nil
+	})
+}
+
+// navigation runs navigate, logging where the page ended up and recording
+// it in res when it isn't nil. The URL is only looked up when it is logged
+// or recorded.
+func navigation(log *slog.Logger, res *Result, navigate chromedp.Action) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		log.Debug("navigation started")
+		start := time.Now()
+		if err := navigate.Do(ctx); err != nil {
+			return err
+		}
+		elapsed := time.Since(start)
+
+		if res == nil && !log.Enabled(ctx, slog.LevelInfo) {
+			return nil
+		}
+		var url string
+		_ = chromedp.Location(&url).Do(ctx)
+		if res != nil {
+			res.NavigationDuration, res.URL = elapsed, url
+		}
+		log.Info("navigation finished", "url", url, "duration", elapsed)
+		return nil
+	})
+}
+
+// printed logs the size of the PDF and how long printing took, which is
+// also recorded in the Result carried by ctx
+func printed(ctx context.Context, log *slog.Logger, size int64, elapsed time.Duration) {
+	if res := resultFrom(ctx); res != nil {
+		res.PrintDuration = elapsed
+	}
+	log.Info("PDF generated", "bytes", size, "duration", elapsed)
+}
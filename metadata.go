@@ -0,0 +1,72 @@
+package htmlgopdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// hasMetadata reports whether the options set any document metadata
+func hasMetadata(opts *PDFOptions) bool {
+	return opts.Title != "" || opts.Author != "" || opts.Subject != "" || len(opts.Keywords) > 0
+}
+
+// applyMetadata writes the metadata set in opts to the Info dictionary of
+// data, keeping the entries Chrome wrote, such as Producer, that opts
+// doesn't replace
+func applyMetadata(data []byte, opts *PDFOptions) ([]byte, error) {
+	if !hasMetadata(opts) {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply metadata: %w", err)
+	}
+
+	info := pdfDict{}
+	for k, v := range doc.dict(doc.trailer["Info"]) {
+		info[k] = v
+	}
+
+	fields := map[pdfName]string{
+		"Title":    opts.Title,
+		"Author":   opts.Author,
+		"Subject":  opts.Subject,
+		"Keywords": strings.Join(opts.Keywords, ", "),
+	}
+	for name, value := range fields {
+		if value != "" {
+			info[name] = textString(value)
+		}
+	}
+
+	if ref, ok := doc.trailer["Info"].(pdfRef); ok {
+		doc.objects[ref.Num] = info
+	} else {
+		doc.trailer["Info"] = doc.add(info)
+	}
+
+	return doc.bytes(), nil
+}
+
+// textString encodes s as a PDF text string: as is when it is plain ASCII,
+// and as UTF-16BE with a byte order mark otherwise
+func textString(s string) pdfString {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return pdfString(s)
+	}
+
+	encoded := []byte{0xFE, 0xFF}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		encoded = append(encoded, byte(unit>>8), byte(unit))
+	}
+	return encoded
+}
@@ -0,0 +1,206 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrNetworkIdleTimeout is returned when the page still loads resources
+// when the network idle timeout runs out
+var ErrNetworkIdleTimeout = errors.New("timed out waiting for network idle")
+
+// networkIdleTime is how long the network must be idle when NetworkIdle
+// isn't set, the same as Chrome's networkIdle lifecycle event
+const networkIdleTime = 500 * time.Millisecond
+
+// networkIdleMaxWait is how long NetworkIdle waits when NetworkIdleMaxWait
+// isn't set
+const networkIdleMaxWait = 10 * time.Second
+
+// networkIdlePoll is the shortest interval the network is checked for idle
+// at, so that a tiny NetworkIdle doesn't turn the wait into a busy loop
+const networkIdlePoll = 10 * time.Millisecond
+
+// networkTracker counts the requests a tab has in flight
+type networkTracker struct {
+	mu          sync.Mutex
+	inFlight    map[network.RequestID]bool
+	maxInFlight int       // Requests that may be in flight while idle
+	idleSince   time.Time // Zero while more than maxInFlight requests are in flight
+}
+
+// newNetworkTracker returns a tracker when the options wait for network
+// idle, and nil otherwise
+func (g *Generator) newNetworkTracker() *networkTracker {
+	if g.options.NetworkIdleTimeout <= 0 && g.options.NetworkIdle <= 0 {
+		return nil
+	}
+
+	return &networkTracker{
+		inFlight:    make(map[network.RequestID]bool),
+		maxInFlight: g.options.NetworkIdleMaxInFlight,
+		idleSince:   time.Now(),
+	}
+}
+
+// track starts counting the tab's requests. It must run before navigating
+// so that no request is missed.
+func (t *networkTracker) track() chromedp.Action {
+	if t == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			switch ev := ev.(type) {
+			case *network.EventRequestWillBeSent:
+				// data: URLs never touch the network
+				if !strings.HasPrefix(ev.Request.URL, "data:") {
+					t.inFlight[ev.RequestID] = true
+				}
+			case *network.EventLoadingFinished:
+				delete(t.inFlight, ev.RequestID)
+			case *network.EventLoadingFailed:
+				delete(t.inFlight, ev.RequestID)
+			default:
+				return
+			}
+
+			switch {
+			case len(t.inFlight) > t.maxInFlight:
+				t.idleSince = time.Time{}
+			case t.idleSince.IsZero():
+				t.idleSince = time.Now()
+			}
+		})
+		return nil
+	})
+}
+
+// wait blocks until the network has been idle for idleTime. After timeout
+// it fails with ErrNetworkIdleTimeout, or with warn set calls it and
+// proceeds.
+func (t *networkTracker) wait(idleTime, timeout time.Duration, warn func(format string, args ...any)) chromedp.Action {
+	if t == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+
+		// NewTicker panics on an interval that isn't positive
+		ticker := time.NewTicker(max(idleTime/10, networkIdlePoll))
+		defer ticker.Stop()
+
+		for {
+			t.mu.Lock()
+			idle := !t.idleSince.IsZero() && time.Since(t.idleSince) >= idleTime
+			pending := len(t.inFlight)
+			t.mu.Unlock()
+
+			if idle {
+				return nil
+			}
+
+			select {
+			case <-ticker.C:
+			case <-deadline.C:
+				if warn != nil {
+					warn("network not idle after %s, %d requests still pending; printing anyway", timeout, pending)
+					return nil
+				}
+				return fmt.Errorf("%w after %s, %d requests still pending", ErrNetworkIdleTimeout, timeout, pending)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+}
+
+// HTTPError is returned when FailOnHTTPError is set and the document was
+// served with a status other than 2xx
+type HTTPError struct {
+	StatusCode int    // Status of the final response, after redirects
+	URL        string // URL of the final response, after redirects
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("page returned HTTP %d for %s", e.StatusCode, e.URL)
+}
+
+// Is makes errors.Is(err, ErrNavigation) report true
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrNavigation
+}
+
+// documentWatcher remembers the status of the main document's response
+type documentWatcher struct {
+	mu     sync.Mutex
+	status int64
+	url    string
+}
+
+// newDocumentWatcher returns a watcher when the options fail on HTTP
+// errors, and nil otherwise
+func (g *Generator) newDocumentWatcher() *documentWatcher {
+	if !g.options.FailOnHTTPError {
+		return nil
+	}
+	return &documentWatcher{}
+}
+
+// watch starts recording the main frame's document responses. Redirects
+// don't produce one, so the last response recorded is the final one.
+func (d *documentWatcher) watch() chromedp.Action {
+	if d == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+		mainFrame := tree.Frame.ID
+
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			if ev, ok := ev.(*network.EventResponseReceived); ok &&
+				ev.Type == network.ResourceTypeDocument && ev.FrameID == mainFrame {
+				d.mu.Lock()
+				d.status, d.url = ev.Response.Status, ev.Response.URL
+				d.mu.Unlock()
+			}
+		})
+		return nil
+	})
+}
+
+// check fails with an HTTPError when the document wasn't served with a
+// 2xx status. Documents loaded without HTTP, such as HTML content, pass.
+func (d *documentWatcher) check() chromedp.Action {
+	if d == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if d.status != 0 && (d.status < 200 || d.status >= 300) {
+			return &HTTPError{StatusCode: int(d.status), URL: d.url}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,35 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestNetworkWaitTinyIdleTime(t *testing.T) {
+	for _, idleTime := range []time.Duration{0, time.Nanosecond, 9 * time.Nanosecond, time.Millisecond} {
+		t.Run(idleTime.String(), func(t *testing.T) {
+			tracker := &networkTracker{inFlight: make(map[network.RequestID]bool), idleSince: time.Now()}
+			if err := tracker.wait(idleTime, time.Second, nil).Do(context.Background()); err != nil {
+				t.Errorf("wait() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNetworkWaitTimeout(t *testing.T) {
+	// A request that never finishes
+	tracker := &networkTracker{inFlight: map[network.RequestID]bool{"1": true}}
+
+	start := time.Now()
+	err := tracker.wait(time.Nanosecond, 50*time.Millisecond, nil).Do(context.Background())
+	if !errors.Is(err, ErrNetworkIdleTimeout) {
+		t.Errorf("wait() error = %v, want ErrNetworkIdleTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait() took %s to time out after 50ms", elapsed)
+	}
+}
@@ -1,40 +1,199 @@
 package htmlgopdf
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
 )
 
 // PDFOptions represents configuration options for PDF generation
 type PDFOptions struct {
 	// Page settings
-	Format string  `json:"format,omitempty"` // A4, A3, Letter, etc.
+	Format string  `json:"format"`           // A4, Letter, etc., or Custom to use Width and Height
 	Width  float64 `json:"width,omitempty"`  // Paper width in inches
 	Height float64 `json:"height,omitempty"` // Paper height in inches
 
 	// Margins in inches
-	MarginTop    float64 `json:"marginTop,omitempty"`    // Top margin
-	MarginBottom float64 `json:"marginBottom,omitempty"` // Bottom margin
-	MarginLeft   float64 `json:"marginLeft,omitempty"`   // Left margin
-	MarginRight  float64 `json:"marginRight,omitempty"`  // Right margin
+	MarginTop    float64 `json:"marginTop"`    // Top margin
+	MarginBottom float64 `json:"marginBottom"` // Bottom margin
+	MarginLeft   float64 `json:"marginLeft"`   // Left margin
+	MarginRight  float64 `json:"marginRight"`  // Right margin
 
 	// Layout options
-	Landscape       bool `json:"landscape,omitempty"`       // Landscape orientation
-	PrintBackground bool `json:"printBackground,omitempty"` // Include background graphics
+	Landscape       bool `json:"landscape,omitempty"` // Landscape orientation
+	PrintBackground bool `json:"printBackground"`     // Include background graphics
+
+	PageRanges string `json:"pageRanges,omitempty"` // Pages to print, e.g. "1-3,5,7-9"; empty prints all
+
+	// Emulation
+	ForcePrintMedia bool `json:"forcePrintMedia,omitempty"` // Apply @media print rules while the page loads and runs scripts
+	ViewportWidth   int  `json:"viewportWidth,omitempty"`   // Width in CSS pixels the page is laid out at, 800 when zero
+	ViewportHeight  int  `json:"viewportHeight,omitempty"`  // Height in CSS pixels of the viewport, 600 when zero
+
+	DeviceScaleFactor float64 `json:"deviceScaleFactor,omitempty"` // Device pixel ratio from 0.5 to 3, e.g. 2 for sharper images; 0 keeps the default
+	Device            string  `json:"device,omitempty"`            // Device to emulate, e.g. "iPhone 14"; viewport settings override its screen
+	UserAgent         string  `json:"userAgent,omitempty"`         // User-Agent sent instead of Chrome's, also overriding the device's
+	AcceptLanguage    string  `json:"acceptLanguage,omitempty"`    // Accept-Language sent and reported by navigator.languages, e.g. "de-DE,de"
+	Platform          string  `json:"platform,omitempty"`          // Platform reported by navigator.platform, e.g. "Win32"
 
 	// Scale and quality
-	Scale float64 `json:"scale,omitempty"` // Scale of the webpage rendering (0.1 to 2)
+	Scale float64 `json:"scale"` // Scale of the webpage rendering (0.1 to 2); 0 keeps the default of 1
 
 	// Header and footer
 	DisplayHeaderFooter bool   `json:"displayHeaderFooter,omitempty"` // Display header and footer
 	HeaderTemplate      string `json:"headerTemplate,omitempty"`      // HTML template for header
 	FooterTemplate      string `json:"footerTemplate,omitempty"`      // HTML template for footer
 
+	SkipFirstPageHeaderFooter bool                       `json:"skipFirstPageHeaderFooter,omitempty"` // Leave the header and footer off the first page, e.g. a title page
+	HeaderFooterParity        *HeaderFooterParityOptions `json:"headerFooterParity,omitempty"`        // Plain text headers and footers that differ between odd and even pages
+	StartPageNumber           int                        `json:"startPageNumber,omitempty"`           // Number of the first page in headers, footers and PageNumbers; 1 when zero
+
 	// Wait conditions
-	WaitForSelector string        `json:"-"` // CSS selector to wait for before generating PDF
-	WaitTime        time.Duration `json:"-"` // Additional wait time
+	WaitForSelector string        `json:"waitForSelector,omitempty"` // CSS selector to wait for before generating PDF
+	WaitTime        time.Duration `json:"waitTime"`                  // Additional wait time, in milliseconds in JSON
+
+	WaitForAllSelectors   []string `json:"waitForAllSelectors,omitempty"`   // CSS selectors that must all be visible
+	WaitForAnySelectors   []string `json:"waitForAnySelectors,omitempty"`   // CSS selectors of which one must be visible
+	WaitForExpression     string   `json:"waitForExpression,omitempty"`     // JavaScript expression to wait for to be truthy, e.g. "window.__reportReady === true"
+	WaitForHiddenSelector string   `json:"waitForHiddenSelector,omitempty"` // CSS selector of an element, e.g. a loading spinner, to wait for to be removed or hidden
+
+	WaitForFunction        string        `json:"waitForFunction,omitempty"`        // JavaScript expression, possibly returning a promise, polled until truthy
+	WaitForFunctionPoll    time.Duration `json:"waitForFunctionPoll,omitempty"`    // Interval between evaluations of WaitForFunction; 100ms when zero. In milliseconds in JSON
+	WaitForFunctionTimeout time.Duration `json:"waitForFunctionTimeout,omitempty"` // Wait up to this long for WaitForFunction; until shortly before Timeout when zero. In milliseconds in JSON
+
+	WaitForImages        bool          `json:"waitForImages,omitempty"`        // Wait for images, including CSS backgrounds, to load or fail, printing with a warning after WaitForImagesTimeout
+	WaitForImagesTimeout time.Duration `json:"waitForImagesTimeout,omitempty"` // Wait up to this long for images; 10s when zero. In milliseconds in JSON
+
+	WaitForFonts        bool          `json:"waitForFonts,omitempty"`        // Wait for web fonts to load right before printing, printing with a warning after WaitForFontsTimeout
+	WaitForFontsTimeout time.Duration `json:"waitForFontsTimeout,omitempty"` // Wait up to this long for web fonts; 5s when zero. In milliseconds in JSON
+
+	NetworkIdleTimeout     time.Duration `json:"networkIdleTimeout,omitempty"`     // Wait up to this long for the network to be idle, failing after it; 0 doesn't wait. In milliseconds in JSON
+	NetworkIdle            time.Duration `json:"networkIdle,omitempty"`            // Wait for the network to be idle this long, proceeding with a warning after NetworkIdleMaxWait; 500ms for NetworkIdleTimeout when zero. In milliseconds in JSON
+	NetworkIdleMaxInFlight int           `json:"networkIdleMaxInFlight,omitempty"` // Requests that may still be in flight for the network to count as idle, e.g. for long-polling connections
+	NetworkIdleMaxWait     time.Duration `json:"networkIdleMaxWait,omitempty"`     // Wait up to this long for NetworkIdle before proceeding anyway; 10s when zero. In milliseconds in JSON
+
+	// Page manipulation
+	CSSSnippets           []string `json:"cssSnippets,omitempty"`           // CSS added to the page once it has loaded, before the wait conditions
+	HideSelectors         []string `json:"hideSelectors,omitempty"`         // CSS selectors of elements hidden with display: none, e.g. navigation bars and cookie banners
+	RemoveSelectors       []string `json:"removeSelectors,omitempty"`       // CSS selectors of elements removed from the DOM after the wait conditions and InjectJS
+	StrictRemoveSelectors bool     `json:"strictRemoveSelectors,omitempty"` // Fail when one of RemoveSelectors matches nothing, rather than warning
+	JSSnippets            []string `json:"jsSnippets,omitempty"`            // JavaScript evaluated in order once the page is ready, before printing
+
+	PreloadScripts    []string `json:"preloadScripts,omitempty"`    // JavaScript evaluated in order once the page has loaded, before the wait conditions
+	PreloadScriptURLs []string `json:"preloadScriptURLs,omitempty"` // Scripts loaded in order before PreloadScripts; turns off the page's Content-Security-Policy
+
+	// Hooks, called with the tab's chromedp context
+	BeforeNavigate func(ctx context.Context) error `json:"-"` // Called once the tab is set up, before it loads the page
+	BeforePrint    func(ctx context.Context) error `json:"-"` // Called after the wait conditions, CSS and JavaScript, right before printing
+	ExtraActions   []chromedp.Action               `json:"-"` // Run in order after the wait conditions, CSS and JavaScript, before BeforePrint
+
+	EvalBeforePrint []string `json:"evalBeforePrint,omitempty"` // JavaScript expressions evaluated in order right before printing, after BeforePrint
+
+	// Document metadata, written to the PDF's Info dictionary
+	Title    string   `json:"title,omitempty"`    // Title shown by PDF viewers instead of the page's <title>
+	Author   string   `json:"author,omitempty"`   // Author of the document
+	Subject  string   `json:"subject,omitempty"`  // Subject of the document
+	Keywords []string `json:"keywords,omitempty"` // Keywords document management systems index the PDF by
+
+	Archival string `json:"archival,omitempty"` // PDF/A level to produce, PDFA1b or PDFA2b, for archiving
+
+	Encryption *EncryptionOptions `json:"encryption,omitempty"` // Passwords protecting the PDF, nil to leave it unencrypted
+
+	Watermark      *WatermarkOptions      `json:"watermark,omitempty"`      // Text drawn across every page, e.g. "DRAFT"
+	ImageWatermark *ImageWatermarkOptions `json:"imageWatermark,omitempty"` // Image, such as a logo, drawn on every page
+
+	PageNumbers *PageNumberOptions `json:"pageNumbers,omitempty"` // Page numbers drawn onto every page without Chrome's header and footer
+
+	BuildOutline bool `json:"buildOutline,omitempty"` // Add bookmarks for the page's h1 to h6 headings
 
 	// Timeout
-	Timeout time.Duration `json:"-"` // Context timeout
+	Timeout time.Duration `json:"timeout"` // Context timeout, in milliseconds in JSON
+
+	// Retry settings
+	Retries      int           `json:"retries,omitempty"`      // Extra attempts after a transient failure, such as Chrome failing to start
+	RetryBackoff time.Duration `json:"retryBackoff,omitempty"` // Wait before the first retry, doubled for each one after it. In milliseconds in JSON
+
+	// Input limits
+	MaxInputSize int64 `json:"maxInputSize,omitempty"` // Maximum HTML size in bytes accepted by FromReader, 0 for no limit
+
+	// Diagnostics
+	OnWarning func(warning string) `json:"-"` // Called for problems that don't fail the render, e.g. ignored certificate errors
+	Logger    *slog.Logger         `json:"-"` // Receives debug and info lines about each render, nothing is logged when nil
+	Tracer    Tracer               `json:"-"` // Records the phases of each render as spans, see WithTelemetry
+
+	OnProgress func(Progress) `json:"-"` // Called on the rendering goroutine as generation moves through its stages
+
+	CaptureConsole bool                 `json:"captureConsole,omitempty"` // Report the page's console errors and uncaught exceptions to OnWarning
+	OnConsole      func(ConsoleMessage) `json:"-"`                        // Called with every console message and uncaught exception of the page
+
+	FailOnJSError         bool     `json:"failOnJSError,omitempty"`         // Fail with a JSError when the page throws an uncaught exception before printing
+	IgnoreJSErrors        []string `json:"ignoreJSErrors,omitempty"`        // Substrings of exception messages or script URLs FailOnJSError ignores
+	IgnoreJSErrorPatterns []string `json:"ignoreJSErrorPatterns,omitempty"` // Regular expressions of exception messages or script URLs FailOnJSError ignores
+
+	DebugDir string `json:"debugDir,omitempty"` // Directory a screenshot and the DOM of the page are written to when a render fails after navigating
+
+	// Asset settings
+	StrictAssets bool `json:"strictAssets,omitempty"` // Fail FromFS and FromHTMLWithAssets renders that reference missing assets
+
+	// Output file settings
+	FileMode   os.FileMode `json:"fileMode,omitempty"`   // Permissions of files written by FromHTMLToFile and FromURLToFile, 0644 when zero
+	CreateDirs bool        `json:"createDirs,omitempty"` // Create missing parent directories of the output file
+
+	// Browser settings
+	ChromePath string `json:"chromePath,omitempty"` // Path to the Chrome/Chromium executable
+	RemoteURL  string `json:"remoteURL,omitempty"`  // DevTools endpoint of an already running Chrome (ws:// or http://)
+
+	ChromeFlags map[string]interface{} `json:"chromeFlags,omitempty"` // Extra command line flags, e.g. "disable-dev-shm-usage": true
+	NoSandbox   bool                   `json:"noSandbox,omitempty"`   // Disable Chrome's sandbox, needed in many containers
+
+	AllowFileAccess bool `json:"allowFileAccess,omitempty"` // Let HTML content reference local files through file:// URLs
+
+	// Request settings
+	FailOnHTTPError bool `json:"failOnHTTPError,omitempty"` // Fail with an HTTPError when the document is served with a non-2xx status
+
+	FailOnResourceError  bool                `json:"failOnResourceError,omitempty"`  // Fail with a ResourceError when an image, stylesheet or other subresource fails to load
+	IgnoreResourceErrors []string            `json:"ignoreResourceErrors,omitempty"` // URLs of subresources allowed to fail, with * as wildcard, e.g. "*/beacon*"
+	OnResourceError      func(ResourceError) `json:"-"`                              // Called with every subresource that fails to load
+
+	MaxRedirects            int  `json:"maxRedirects,omitempty"`            // Redirects the document may follow, 0 for Chrome's own limit, -1 for none
+	SameOriginRedirectsOnly bool `json:"sameOriginRedirectsOnly,omitempty"` // Refuse redirects of the document to another origin
+
+	BaseURL string `json:"baseURL,omitempty"` // Directory relative links in HTML content resolve against, unless it has its own <base href>
+
+	Headers map[string]string      `json:"headers,omitempty"` // Extra HTTP headers sent with the document and same-origin requests, e.g. Authorization
+	Cookies []*network.CookieParam `json:"cookies,omitempty"` // Cookies set in the browser before navigating
+
+	BasicAuthUsername string `json:"basicAuthUsername,omitempty"` // Username answered to HTTP authentication challenges
+	BasicAuthPassword string `json:"basicAuthPassword,omitempty"` // Password answered to HTTP authentication challenges
+
+	// Network policy
+	DisableNetwork   bool             `json:"disableNetwork,omitempty"` // Block every request except the document itself and caller-provided assets
+	OnBlockedRequest func(url string) `json:"-"`                        // Called with the URL of every request the network policy blocks
+
+	AllowedHosts        []string `json:"allowedHosts,omitempty"`        // Hosts subresources may be loaded from, e.g. "cdn.example.com" or "*.example.com"; empty allows all
+	BlockedURLPatterns  []string `json:"blockedURLPatterns,omitempty"`  // URLs subresources may not be loaded from, with * as wildcard, e.g. "*://*.doubleclick.net/*"
+	StrictNetworkPolicy bool     `json:"strictNetworkPolicy,omitempty"` // Apply AllowedHosts and BlockedURLPatterns to the document too, failing the render when it is blocked
+	BlockResourceTypes  []string `json:"blockResourceTypes,omitempty"`  // CDP resource types not to load, e.g. "Image", "Font", "Media"
+
+	// TLS settings
+	IgnoreTLSErrors bool         `json:"ignoreTLSErrors,omitempty"` // Accept invalid certificates, e.g. self-signed ones in staging
+	HTTPClient      *http.Client `json:"-"`                         // Client that makes the page's HTTP requests instead of Chrome, e.g. one with a client certificate
+
+	// Proxy settings
+	ProxyServer     string   `json:"proxyServer,omitempty"`     // http://, https:// or socks5:// proxy, optionally with user:pass@
+	ProxyBypassList []string `json:"proxyBypassList,omitempty"` // Hosts that skip the proxy, e.g. "*.internal"
 }
 
 // DefaultOptions returns sensible defaults for PDF generation
@@ -52,3 +211,413 @@ func DefaultOptions() *PDFOptions {
 		Timeout:         time.Second * 30,
 	}
 }
+
+// Clone returns a deep copy of the options, so that changing the copy's
+// slices, maps and nested options leaves o alone. Callbacks, the logger,
+// the HTTP client and chromedp actions are shared.
+func (o *PDFOptions) Clone() *PDFOptions {
+	if o == nil {
+		return nil
+	}
+	c := *o
+
+	for _, s := range []*[]string{
+		&c.WaitForAllSelectors, &c.WaitForAnySelectors, &c.CSSSnippets, &c.HideSelectors, &c.RemoveSelectors, &c.JSSnippets,
+		&c.PreloadScripts, &c.PreloadScriptURLs, &c.EvalBeforePrint, &c.Keywords,
+		&c.IgnoreJSErrors, &c.IgnoreJSErrorPatterns, &c.IgnoreResourceErrors,
+		&c.AllowedHosts, &c.BlockedURLPatterns, &c.BlockResourceTypes, &c.ProxyBypassList,
+	} {
+		*s = slices.Clone(*s)
+	}
+	c.ExtraActions = slices.Clone(o.ExtraActions)
+	c.ChromeFlags = maps.Clone(o.ChromeFlags)
+	c.Headers = maps.Clone(o.Headers)
+
+	if o.Cookies != nil {
+		c.Cookies = make([]*network.CookieParam, len(o.Cookies))
+		for i, cookie := range o.Cookies {
+			if cookie != nil {
+				copied := *cookie
+				c.Cookies[i] = &copied
+			}
+		}
+	}
+
+	if o.Encryption != nil {
+		e := *o.Encryption
+		if e.Permissions != nil {
+			p := *e.Permissions
+			e.Permissions = &p
+		}
+		c.Encryption = &e
+	}
+	if o.Watermark != nil {
+		w := *o.Watermark
+		c.Watermark = &w
+	}
+	if o.ImageWatermark != nil {
+		w := *o.ImageWatermark
+		w.PNG = slices.Clone(w.PNG)
+		c.ImageWatermark = &w
+	}
+	if o.PageNumbers != nil {
+		p := *o.PageNumbers
+		c.PageNumbers = &p
+	}
+	if o.HeaderFooterParity != nil {
+		p := *o.HeaderFooterParity
+		c.HeaderFooterParity = &p
+	}
+
+	return &c
+}
+
+// Merge returns a copy of o with every field override sets to a non-zero
+// value replaced by override's, e.g. to apply per-document settings to a
+// shared base. Neither o nor override is changed. Since only non-zero
+// fields count, override can't turn a setting of o back off, such as
+// PrintBackground or a margin, to false or 0. Set such fields on the result
+// instead; MergeEnv and LoadOptionsFromJSON do apply false and 0.
+func (o *PDFOptions) Merge(override *PDFOptions) *PDFOptions {
+	merged := o.Clone()
+	if override == nil {
+		return merged
+	}
+
+	src := reflect.ValueOf(override.Clone()).Elem()
+	dst := reflect.ValueOf(merged).Elem()
+	for i := range src.NumField() {
+		if field := src.Field(i); !field.IsZero() {
+			dst.Field(i).Set(field)
+		}
+	}
+	return merged
+}
+
+// Validate checks the options for contradictory values and values Chrome
+// would reject, so that mistakes are reported, as ErrInvalidOptions, before
+// a browser is launched. Every generation method calls it first.
+func (o *PDFOptions) Validate() error {
+	if err := o.check(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	return nil
+}
+
+// check returns the first problem with the options
+func (o *PDFOptions) check() error {
+	if err := o.checkPage(); err != nil {
+		return err
+	}
+
+	if o.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %s: must be positive", o.Timeout)
+	}
+
+	for _, d := range []time.Duration{o.NetworkIdleTimeout, o.NetworkIdle, o.NetworkIdleMaxWait} {
+		if d < 0 {
+			return fmt.Errorf("network idle durations must not be negative, got %s", d)
+		}
+	}
+
+	if o.WaitForFunctionPoll < 0 || o.WaitForFunctionTimeout < 0 {
+		return fmt.Errorf("wait for function durations must not be negative")
+	}
+	if o.WaitForImagesTimeout < 0 {
+		return fmt.Errorf("wait for images timeout must not be negative, got %s", o.WaitForImagesTimeout)
+	}
+	if o.WaitForFontsTimeout < 0 {
+		return fmt.Errorf("wait for fonts timeout must not be negative, got %s", o.WaitForFontsTimeout)
+	}
+	if o.NetworkIdleMaxInFlight < 0 {
+		return fmt.Errorf("network idle max in flight must not be negative, got %d", o.NetworkIdleMaxInFlight)
+	}
+
+	if o.PageRanges != "" {
+		if err := validatePageRanges(o.PageRanges); err != nil {
+			return err
+		}
+	}
+
+	if err := validateHeaders(o.Headers); err != nil {
+		return err
+	}
+
+	for _, c := range o.Cookies {
+		if err := validateCookie(c); err != nil {
+			return err
+		}
+	}
+
+	if o.DeviceScaleFactor != 0 && (o.DeviceScaleFactor < 0.5 || o.DeviceScaleFactor > 3) {
+		return fmt.Errorf("invalid device scale factor %g: must be between 0.5 and 3", o.DeviceScaleFactor)
+	}
+
+	if _, ok := devices[o.Device]; o.Device != "" && !ok {
+		return fmt.Errorf("unknown device %q", o.Device)
+	}
+
+	if _, ok := pdfaParts[o.Archival]; o.Archival != "" && !ok {
+		return fmt.Errorf("unknown PDF/A level %q: must be %s or %s", o.Archival, PDFA1b, PDFA2b)
+	}
+
+	if o.Encryption != nil {
+		if o.Archival != "" {
+			return fmt.Errorf("cannot encrypt a %s document: PDF/A doesn't allow encryption", o.Archival)
+		}
+		if err := o.Encryption.validate(); err != nil {
+			return err
+		}
+	}
+
+	if o.Watermark != nil {
+		if o.Archival != "" {
+			return fmt.Errorf("cannot watermark a %s document: watermarks use fonts that aren't embedded", o.Archival)
+		}
+		if err := o.Watermark.validate(); err != nil {
+			return err
+		}
+	}
+
+	if o.PageNumbers != nil {
+		if o.Archival != "" {
+			return fmt.Errorf("cannot number the pages of a %s document: page numbers use fonts that aren't embedded", o.Archival)
+		}
+		if err := o.PageNumbers.validate(); err != nil {
+			return err
+		}
+	}
+
+	if o.StartPageNumber < 0 {
+		return fmt.Errorf("start page number must not be negative, got %d", o.StartPageNumber)
+	}
+
+	if o.HeaderFooterParity != nil {
+		if o.Archival != "" {
+			return fmt.Errorf("cannot add headers and footers to a %s document: they use fonts that aren't embedded", o.Archival)
+		}
+		if err := o.HeaderFooterParity.validate(); err != nil {
+			return err
+		}
+	}
+
+	if o.ImageWatermark != nil {
+		if err := o.ImageWatermark.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, selector := range o.HideSelectors {
+		if strings.ContainsAny(selector, "{}") || strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("invalid hide selector %q", selector)
+		}
+	}
+
+	for _, selector := range o.RemoveSelectors {
+		if strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("invalid remove selector %q", selector)
+		}
+	}
+
+	for _, pattern := range o.IgnoreJSErrorPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid JS error pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, t := range o.BlockResourceTypes {
+		if !validResourceType(t) {
+			return fmt.Errorf("invalid resource type %q", t)
+		}
+	}
+
+	return nil
+}
+
+// checkPage checks the paper size, margins and scale
+func (o *PDFOptions) checkPage() error {
+	switch {
+	case o.Format == "" || o.Format == FormatCustom:
+		if o.Width <= 0 || o.Height <= 0 {
+			return fmt.Errorf("invalid paper size %gx%g: width and height must be positive without a named format", o.Width, o.Height)
+		}
+	case !knownFormat(o.Format):
+		return fmt.Errorf("unknown paper format %q", o.Format)
+	case o.Width != 0 || o.Height != 0:
+		return fmt.Errorf("paper format %s conflicts with width and height %gx%g: use Custom for a custom size", o.Format, o.Width, o.Height)
+	}
+
+	margins := []struct {
+		side  string
+		value float64
+	}{{"top", o.MarginTop}, {"bottom", o.MarginBottom}, {"left", o.MarginLeft}, {"right", o.MarginRight}}
+	for _, m := range margins {
+		if m.value < 0 {
+			return fmt.Errorf("invalid %s margin %g: must not be negative", m.side, m.value)
+		}
+	}
+
+	if o.Scale != 0 && (o.Scale < 0.1 || o.Scale > 2) {
+		return fmt.Errorf("invalid scale %g: must be between 0.1 and 2", o.Scale)
+	}
+
+	return nil
+}
+
+// validatePageRanges checks a comma-separated list of page numbers and
+// N-M ranges, e.g. "1-3,5,7-9"
+func validatePageRanges(ranges string) error {
+	for _, part := range strings.Split(ranges, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+
+		from, err := parsePageNumber(first)
+		if err != nil {
+			return fmt.Errorf("invalid page range %q: %w", ranges, err)
+		}
+		if !isRange {
+			continue
+		}
+
+		to, err := parsePageNumber(last)
+		if err != nil {
+			return fmt.Errorf("invalid page range %q: %w", ranges, err)
+		}
+		if from > to {
+			return fmt.Errorf("invalid page range %q: %d-%d ends before it starts", ranges, from, to)
+		}
+	}
+
+	return nil
+}
+
+// firstPage returns the first page the ranges print, 1 when they are empty
+func firstPage(ranges string) int {
+	if ranges == "" {
+		return 1
+	}
+
+	first := 0
+	for _, part := range strings.Split(ranges, ",") {
+		from, _, _ := strings.Cut(strings.TrimSpace(part), "-")
+		if n, err := parsePageNumber(from); err == nil && (first == 0 || n < first) {
+			first = n
+		}
+	}
+	return max(first, 1)
+}
+
+// printedIndex returns the index in the PDF of page, a page of the
+// document from 1, and whether the ranges print it at all. Chrome prints
+// the pages in document order.
+func printedIndex(ranges string, page int) (int, bool) {
+	if ranges == "" {
+		return page - 1, page >= 1
+	}
+
+	var spans [][2]int
+	for _, part := range strings.Split(ranges, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		from, err := parsePageNumber(first)
+		if err != nil {
+			continue
+		}
+		to := from
+		if isRange {
+			if to, err = parsePageNumber(last); err != nil {
+				continue
+			}
+		}
+		spans = append(spans, [2]int{from, to})
+	}
+
+	printed := func(n int) bool {
+		return slices.ContainsFunc(spans, func(span [2]int) bool { return span[0] <= n && n <= span[1] })
+	}
+	if !printed(page) {
+		return 0, false
+	}
+
+	index := 0
+	for n := 1; n < page; n++ {
+		if printed(n) {
+			index++
+		}
+	}
+	return index, true
+}
+
+// pageOffset is how much StartPageNumber shifts the page numbers by
+func (o *PDFOptions) pageOffset() int {
+	return max(o.StartPageNumber-1, 0)
+}
+
+// parsePageNumber parses a 1-based page number
+func parsePageNumber(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a page number", s)
+	}
+
+	return n, nil
+}
+
+// validResourceType reports whether t names a CDP resource type, in any case
+func validResourceType(t string) bool {
+	for _, known := range resourceTypes {
+		if strings.EqualFold(t, string(known)) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCookie checks that Chrome can tell which site a cookie belongs to,
+// as it silently drops cookies it can't place
+func validateCookie(c *network.CookieParam) error {
+	if c == nil || c.Name == "" {
+		return fmt.Errorf("invalid cookie: missing name")
+	}
+	if c.Domain == "" && c.URL == "" {
+		return fmt.Errorf("invalid cookie %q: missing domain", c.Name)
+	}
+
+	return nil
+}
+
+// forbiddenHeaders are headers that Chrome manages itself and that can't
+// be overridden
+var forbiddenHeaders = []string{
+	"Host",
+	"Content-Length",
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Transfer-Encoding",
+	"Upgrade",
+	"TE",
+	"Trailer",
+	"Expect",
+	"Cookie", // Use Cookies instead
+}
+
+// validateHeaders rejects forbidden headers and names given twice in
+// different case
+func validateHeaders(headers map[string]string) error {
+	seen := make(map[string]string, len(headers))
+	for name := range headers {
+		for _, forbidden := range forbiddenHeaders {
+			if strings.EqualFold(name, forbidden) {
+				return fmt.Errorf("header %q cannot be set", name)
+			}
+		}
+
+		key := strings.ToLower(name)
+		if other, ok := seen[key]; ok {
+			return fmt.Errorf("header %q is set twice, also as %q", name, other)
+		}
+		seen[key] = name
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package htmlgopdf
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// sharedOptions has every kind of field Clone copies deeply
+func sharedOptions() *PDFOptions {
+	permissions := PermitPrint
+	opts := DefaultOptions()
+	opts.HideSelectors = []string{".nav"}
+	opts.Keywords = []string{"report"}
+	opts.ChromeFlags = map[string]interface{}{"disable-gpu": true}
+	opts.Headers = map[string]string{"Authorization": "Bearer a"}
+	opts.Cookies = []*network.CookieParam{{Name: "session", Value: "a"}}
+	opts.Encryption = &EncryptionOptions{OwnerPassword: "owner", Permissions: &permissions}
+	opts.Watermark = &WatermarkOptions{Text: "DRAFT"}
+	opts.ImageWatermark = &ImageWatermarkOptions{PNG: []byte{1, 2, 3}}
+	opts.PageNumbers = &PageNumberOptions{}
+	opts.HeaderFooterParity = &HeaderFooterParityOptions{OddHeader: "Odd"}
+	return opts
+}
+
+func TestClone(t *testing.T) {
+	opts := sharedOptions()
+	clone := opts.Clone()
+	if !reflect.DeepEqual(clone, opts) {
+		t.Fatalf("Clone() = %+v, want %+v", clone, opts)
+	}
+
+	clone.HideSelectors[0] = ".footer"
+	clone.Keywords = append(clone.Keywords[:0], "changed")
+	clone.ChromeFlags["disable-gpu"] = false
+	clone.Headers["Authorization"] = "Bearer b"
+	clone.Cookies[0].Value = "b"
+	*clone.Encryption.Permissions = PermitAll
+	clone.Encryption.OwnerPassword = "changed"
+	clone.Watermark.Text = "FINAL"
+	clone.ImageWatermark.PNG[0] = 9
+	clone.PageNumbers.Format = "%d"
+	clone.HeaderFooterParity.OddHeader = "Even"
+	clone.MarginTop = 2
+
+	if !reflect.DeepEqual(opts, sharedOptions()) {
+		t.Errorf("changing the clone changed the original: %+v", opts)
+	}
+
+	if (*PDFOptions)(nil).Clone() != nil {
+		t.Error("Clone() of nil options isn't nil")
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	base := sharedOptions()
+	override := &PDFOptions{
+		Format:        FormatLetter,
+		Title:         "Invoice",
+		Timeout:       time.Minute,
+		HideSelectors: []string{".banner"},
+		Headers:       map[string]string{"X-Tenant": "acme"},
+	}
+
+	merged := base.Merge(override)
+
+	want := sharedOptions()
+	want.Format = FormatLetter
+	want.Title = "Invoice"
+	want.Timeout = time.Minute
+	want.HideSelectors = []string{".banner"}
+	want.Headers = map[string]string{"X-Tenant": "acme"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+
+	// Neither side is changed, then or through the result
+	merged.Keywords[0] = "changed"
+	merged.Headers["X-Tenant"] = "other"
+	merged.Encryption.OwnerPassword = "changed"
+	if !reflect.DeepEqual(base, sharedOptions()) {
+		t.Errorf("Merge() changed the receiver: %+v", base)
+	}
+	if override.Headers["X-Tenant"] != "acme" || override.HideSelectors[0] != ".banner" {
+		t.Errorf("Merge() changed the override: %+v", override)
+	}
+
+	// Zero fields of the override leave the receiver's settings on
+	off := base.Merge(&PDFOptions{PrintBackground: false, MarginTop: 0})
+	if !off.PrintBackground || off.MarginTop != base.MarginTop {
+		t.Errorf("Merge() applied zero fields: PrintBackground = %v, MarginTop = %g", off.PrintBackground, off.MarginTop)
+	}
+
+	if merged := base.Merge(nil); !reflect.DeepEqual(merged, base) || merged == base {
+		t.Error("Merge(nil) isn't a copy of the receiver")
+	}
+}
@@ -0,0 +1,43 @@
+//go:build otel
+
+package htmlgopdf
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans
+const tracerName = "github.com/MateoCaicedoW/htmlgopdf"
+
+// WithTelemetry records the Chrome launch, navigation, wait conditions and
+// printing of each render as spans from tp, as children of the span the
+// caller's context carries. Renders whose context carries no span aren't
+// traced. Only available when building with -tags otel.
+func (b *OptionsBuilder) WithTelemetry(tp trace.TracerProvider) *OptionsBuilder {
+	b.options.Tracer = otelTracer{tracer: tp.Tracer(tracerName)}
+	return b
+}
+
+// otelTracer is a Tracer backed by OpenTelemetry
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+// Start starts a span when ctx carries one to be its parent
+func (t otelTracer) Start(ctx context.Context, name string) func(err error) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return func(error) {}
+	}
+
+	_, span := t.tracer.Start(ctx, name)
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
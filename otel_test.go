@@ -0,0 +1,108 @@
+//go:build otel
+
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// spanRecorder is a TracerProvider that records the names and parents of
+// the spans started from it
+type spanRecorder struct {
+	noop.TracerProvider
+
+	mu      sync.Mutex
+	names   []string
+	parents []trace.SpanContext
+}
+
+func (r *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{recorder: r}
+}
+
+// recordingTracer starts no-op spans, recording them with its recorder
+type recordingTracer struct {
+	noop.Tracer
+	recorder *spanRecorder
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.recorder.mu.Lock()
+	t.recorder.names = append(t.recorder.names, name)
+	t.recorder.parents = append(t.recorder.parents, trace.SpanContextFromContext(ctx))
+	t.recorder.mu.Unlock()
+	return t.Tracer.Start(ctx, name, opts...)
+}
+
+// tracedContext returns a context carrying a sampled span for renders to
+// be traced under
+func tracedContext() (context.Context, trace.SpanContext) {
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), parent), parent
+}
+
+func TestPoolTelemetryLaunch(t *testing.T) {
+	recorder := &spanRecorder{}
+	opts := WithOptions().ChromePath("/nonexistent/chrome").WithTelemetry(recorder).options
+
+	// NewPool would fail to launch the browser up front
+	s := &slot{browser: newBrowser(opts)}
+	p := &Pool{generator: NewGenerator(opts), slots: make(chan *slot, 1), all: []*slot{s}}
+	p.slots <- s
+	defer p.Close()
+
+	ctx, parent := tracedContext()
+	if _, err := p.FromHTML(ctx, "<p>Hello</p>"); !errors.Is(err, ErrBrowserStart) {
+		t.Fatalf("FromHTML() error = %v, want ErrBrowserStart", err)
+	}
+
+	if want := []string{spanLaunch}; !slices.Equal(recorder.names, want) {
+		t.Fatalf("started spans %q, want %q", recorder.names, want)
+	}
+	if !recorder.parents[0].Equal(parent) {
+		t.Errorf("launch span is a child of %v, want the caller's span", recorder.parents[0])
+	}
+}
+
+func TestPoolTelemetryRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	recorder := &spanRecorder{}
+	p, err := NewPool(1, WithOptions().WithTelemetry(recorder).options)
+	if errors.Is(err, ErrBrowserStart) {
+		t.Skipf("Chrome is not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	defer p.Close()
+
+	ctx, parent := tracedContext()
+	if _, err := p.FromHTML(ctx, "<p>Hello</p>"); err != nil {
+		t.Fatalf("FromHTML() error = %v", err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if want := []string{spanLaunch, spanNavigate, spanWait, spanPrint}; !slices.Equal(recorder.names, want) {
+		t.Fatalf("started spans %q, want %q", recorder.names, want)
+	}
+	for i, got := range recorder.parents {
+		if !got.Equal(parent) {
+			t.Errorf("%s span is a child of %v, want the caller's span", recorder.names[i], got)
+		}
+	}
+}
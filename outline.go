@@ -0,0 +1,231 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// heading is a heading of the rendered page, an entry of the outline
+type heading struct {
+	Level  int     `json:"level"` // 1 for h1 to 6 for h6
+	Text   string  `json:"text"`
+	Page   int     `json:"page"`   // Page of the document the heading is on, from 1
+	Offset float64 `json:"offset"` // How far down that page the heading is, as a fraction of its height
+}
+
+// headingsScript lists the visible headings of the page in document order,
+// with the page each is printed on. It is called with the height of a page's
+// content box in CSS pixels, and is run with the page laid out as printed:
+// print media at the page's width. A forced page break starts a new page,
+// the rest of the content is cut into pages of that height.
+const headingsScript = `(pageHeight) => {
+	const forced = value => ["page", "always", "left", "right", "recto", "verso"].includes(value);
+	const top = e => e.getBoundingClientRect().top + window.scrollY;
+	const bottom = e => e.getBoundingClientRect().bottom + window.scrollY;
+
+	let page = 1, start = 0;
+	const breakAt = y => {
+		// A break at the top of a page doesn't start another
+		if (y > start) {
+			page += Math.ceil((y - start) / pageHeight);
+			start = y;
+		}
+	};
+
+	const headings = [];
+	const walk = e => {
+		const style = getComputedStyle(e);
+		if (style.display === "none") {
+			return;
+		}
+		if (forced(style.breakBefore)) {
+			breakAt(top(e));
+		}
+		if (/^H[1-6]$/.test(e.tagName) && e.getClientRects().length > 0 && e.textContent.trim() !== "") {
+			const y = Math.max(top(e) - start, 0);
+			headings.push({
+				level: Number(e.tagName[1]),
+				text: e.textContent.replace(/\s+/g, " ").trim(),
+				page: page + Math.floor(y / pageHeight),
+				offset: (y % pageHeight) / pageHeight,
+			});
+		}
+		for (const child of e.children) {
+			walk(child);
+		}
+		if (forced(style.breakAfter)) {
+			breakAt(bottom(e));
+		}
+	};
+	walk(document.body);
+	return headings;
+}`
+
+// headings reads the heading hierarchy of the page in the tab behind ctx.
+// The page is laid out as it is printed while the headings are read, then
+// put back as it was.
+func (g *Generator) headings(ctx context.Context) ([]heading, error) {
+	width, height := g.contentSize()
+
+	err := chromedp.Tasks{
+		emulation.SetEmulatedMedia().WithMedia("print"),
+		emulation.SetDeviceMetricsOverride(int64(math.Round(width)), int64(math.Round(height)), 0, false),
+	}.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lay out headings for print: %w", err)
+	}
+
+	var hs []heading
+	err = chromedp.Evaluate(fmt.Sprintf("(%s)(%g)", headingsScript, height), &hs).Do(ctx)
+	if restoreErr := g.restoreLayout().Do(ctx); err == nil && restoreErr != nil {
+		err = restoreErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headings: %w", err)
+	}
+	return hs, nil
+}
+
+// contentSize returns the width and height in CSS pixels of a page's
+// content box: the paper without its margins, at the print scale
+func (g *Generator) contentSize() (width, height float64) {
+	params := g.printParams()
+
+	paperWidth, paperHeight := params.PaperWidth, params.PaperHeight
+	if paperWidth <= 0 || paperHeight <= 0 {
+		// Chrome's default, US Letter
+		paperWidth, paperHeight = 8.5, 11
+	}
+	if params.Landscape {
+		paperWidth, paperHeight = paperHeight, paperWidth
+	}
+
+	scale := params.Scale
+	if scale == 0 {
+		scale = 1
+	}
+
+	// 96 CSS pixels to the inch
+	width = (paperWidth - params.MarginLeft - params.MarginRight) * 96 / scale
+	height = (paperHeight - params.MarginTop - params.MarginBottom) * 96 / scale
+	return max(width, 1), max(height, 1)
+}
+
+// restoreLayout puts back the media and viewport the options set up
+func (g *Generator) restoreLayout() chromedp.Action {
+	media := emulation.SetEmulatedMedia()
+	if g.options.ForcePrintMedia {
+		media = media.WithMedia("print")
+	}
+	return chromedp.Tasks{
+		media,
+		emulation.ClearDeviceMetricsOverride(),
+		g.deviceMetrics(),
+	}
+}
+
+// outlineItem is an entry of the outline being built, with its children
+type outlineItem struct {
+	ref      pdfRef
+	dict     pdfDict
+	level    int
+	children []*outlineItem
+}
+
+// applyOutline adds an outline to data with an entry per heading, nested
+// by level, unless Chrome already produced one. Each entry points at the
+// heading's page, found among the pages PageRanges prints. Headings on
+// pages left out are skipped.
+func applyOutline(data []byte, hs []heading, opts *PDFOptions) ([]byte, error) {
+	if len(hs) == 0 {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build outline: %w", err)
+	}
+
+	catalog := doc.catalog()
+	if outlines := doc.dict(catalog["Outlines"]); outlines["First"] != nil {
+		return data, nil
+	}
+
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build outline: %w", err)
+	}
+	if len(pages) == 0 {
+		return data, nil
+	}
+
+	root := &outlineItem{ref: doc.add(nil), dict: pdfDict{"Type": pdfName("Outlines")}}
+	stack := []*outlineItem{root}
+	for _, h := range hs {
+		index, ok := printedIndex(opts.PageRanges, h.Page)
+		if !ok {
+			continue
+		}
+		item := &outlineItem{ref: doc.add(nil), level: h.Level, dict: pdfDict{
+			"Title": textString(h.Text),
+			"Dest":  headingDest(doc, pages, index, h.Offset, opts),
+		}}
+
+		// Pop back to the closest heading of a higher level
+		for len(stack) > 1 && stack[len(stack)-1].level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.children = append(parent.children, item)
+		stack = append(stack, item)
+	}
+
+	linkOutline(doc, root)
+	catalog["Outlines"] = root.ref
+	catalog["PageMode"] = pdfName("UseOutlines")
+
+	return doc.bytes(), nil
+}
+
+// headingDest returns a destination offset, a fraction of the height of
+// the content between the margins, down the page at index. A heading
+// estimated past the last page points at the last page.
+func headingDest(doc *pdfDocument, pages []pdfRef, index int, offset float64, opts *PDFOptions) pdfArray {
+	index = min(max(index, 0), len(pages)-1)
+
+	// Margins are in inches, the media box in points
+	rect := mediaBox(doc, doc.dict(pages[index]))
+	top, bottom := rect[3]-opts.MarginTop*72, rect[1]+opts.MarginBottom*72
+	y := top - min(max(offset, 0), 1)*max(top-bottom, 0)
+
+	return pdfArray{pages[index], pdfName("XYZ"), nil, y, nil}
+}
+
+// linkOutline stores item and its descendants with the links between them,
+// every entry open, and returns how many descendants it has
+func linkOutline(doc *pdfDocument, item *outlineItem) int {
+	count := 0
+	for i, child := range item.children {
+		child.dict["Parent"] = item.ref
+		if i > 0 {
+			child.dict["Prev"] = item.children[i-1].ref
+		}
+		if i < len(item.children)-1 {
+			child.dict["Next"] = item.children[i+1].ref
+		}
+		count += 1 + linkOutline(doc, child)
+	}
+
+	if len(item.children) > 0 {
+		item.dict["First"] = item.children[0].ref
+		item.dict["Last"] = item.children[len(item.children)-1].ref
+		item.dict["Count"] = int64(count)
+	}
+	doc.set(item.ref.Num, item.dict)
+
+	return count
+}
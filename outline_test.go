@@ -0,0 +1,244 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/runtime"
+)
+
+func TestPrintedIndex(t *testing.T) {
+	tests := []struct {
+		ranges  string
+		page    int
+		index   int
+		printed bool
+	}{
+		{"", 1, 0, true},
+		{"", 7, 6, true},
+		{"2-3", 1, 0, false},
+		{"2-3", 2, 0, true},
+		{"2-3", 3, 1, true},
+		{"2-3", 4, 0, false},
+		{"5, 1-2", 5, 2, true},
+		{"1-3,2-4", 4, 3, true},
+	}
+
+	for _, tt := range tests {
+		index, printed := printedIndex(tt.ranges, tt.page)
+		if index != tt.index || printed != tt.printed {
+			t.Errorf("printedIndex(%q, %d) = %d, %v, want %d, %v", tt.ranges, tt.page, index, printed, tt.index, tt.printed)
+		}
+	}
+}
+
+func TestApplyOutline(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MarginTop, opts.MarginBottom = 1, 1
+
+	hs := []heading{
+		{Level: 1, Text: "Introduction", Page: 1},
+		{Level: 2, Text: "Background", Page: 1, Offset: 0.5},
+		{Level: 1, Text: "Results", Page: 2, Offset: 0.25},
+		{Level: 1, Text: "Appendix", Page: 5},
+	}
+
+	tests := []struct {
+		name   string
+		ranges string
+		titles []string
+		pages  []int     // Index of the page each entry points at
+		tops   []float64 // Where on the page, in points
+	}{
+		{
+			name:   "every page",
+			titles: []string{"Introduction", "Background", "Results", "Appendix"},
+			// The Appendix, estimated past the end, goes to the last page
+			pages: []int{0, 0, 1, 1},
+			tops:  []float64{720, 396, 558, 720},
+		},
+		{
+			name:   "second page only",
+			ranges: "2",
+			titles: []string{"Results"},
+			pages:  []int{0},
+			tops:   []float64{558},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := opts.Clone()
+			opts.PageRanges = tt.ranges
+
+			data, err := applyOutline(readFixture(t, "classic.pdf"), hs, opts)
+			if err != nil {
+				t.Fatalf("applyOutline() error = %v", err)
+			}
+
+			doc, err := parsePDF(data)
+			if err != nil {
+				t.Fatalf("parsePDF() error = %v", err)
+			}
+			pages, err := doc.pages()
+			if err != nil {
+				t.Fatalf("pages() error = %v", err)
+			}
+
+			var titles []string
+			var walk func(item pdfDict)
+			var n int
+			walk = func(item pdfDict) {
+				for child := item["First"]; child != nil; child = doc.dict(child)["Next"] {
+					entry := doc.dict(child)
+					titles = append(titles, string(entry["Title"].(pdfString)))
+
+					dest := entry["Dest"].(pdfArray)
+					if n < len(tt.pages) {
+						if dest[0] != pages[tt.pages[n]] {
+							t.Errorf("%s points at %v, want page %d", entry["Title"], dest[0], tt.pages[n]+1)
+						}
+						if top := pdfNumber(dest[3]); math.Abs(top-tt.tops[n]) > 0.001 {
+							t.Errorf("%s points at %g points up the page, want %g", entry["Title"], top, tt.tops[n])
+						}
+					}
+					n++
+					walk(entry)
+				}
+			}
+			walk(doc.dict(doc.catalog()["Outlines"]))
+
+			if !slices.Equal(titles, tt.titles) {
+				t.Errorf("outline has %q, want %q", titles, tt.titles)
+			}
+		})
+	}
+}
+
+func TestContentSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		builder       *OptionsBuilder
+		width, height float64
+	}{
+		// A4 less the default margins of 0.4in
+		{"A4", WithOptions(), (8.27 - 0.8) * 96, (11.7 - 0.8) * 96},
+		{"landscape", WithOptions().Landscape(), (11.7 - 0.8) * 96, (8.27 - 0.8) * 96},
+		{"scaled", WithOptions().Format(FormatLetter).Margins(1, 1, 1, 1).Scale(0.5), 6.5 * 96 * 2, 9 * 96 * 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := NewGenerator(tt.builder.options).contentSize()
+			if math.Abs(width-tt.width) > 0.001 || math.Abs(height-tt.height) > 0.001 {
+				t.Errorf("contentSize() = %gx%g, want %gx%g", width, height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+// headingsExecutor records the CDP commands sent through it and answers
+// evaluations with headings
+type headingsExecutor struct {
+	commandRecorder
+	headings string
+}
+
+func (e *headingsExecutor) Execute(ctx context.Context, method string, params, res any) error {
+	e.commandRecorder.Execute(ctx, method, params, res)
+	if ret, ok := res.(*runtime.EvaluateReturns); ok {
+		ret.Result = &runtime.RemoteObject{Type: runtime.TypeObject, Value: []byte(e.headings)}
+	}
+	return nil
+}
+
+func TestHeadingsPrintLayout(t *testing.T) {
+	g := NewGenerator(WithOptions().Format(FormatLetter).Margins(1, 1, 1, 1).ViewportWidth(1280).options)
+	executor := &headingsExecutor{headings: `[{"level":1,"text":"Results","page":3,"offset":0.5}]`}
+
+	hs, err := g.headings(cdp.WithExecutor(context.Background(), executor))
+	if err != nil {
+		t.Fatalf("headings() error = %v", err)
+	}
+	if len(hs) != 1 || hs[0] != (heading{Level: 1, Text: "Results", Page: 3, Offset: 0.5}) {
+		t.Errorf("headings() = %+v", hs)
+	}
+
+	want := []string{
+		emulation.CommandSetEmulatedMedia,
+		emulation.CommandSetDeviceMetricsOverride,
+		runtime.CommandEvaluate,
+		emulation.CommandSetEmulatedMedia,
+		emulation.CommandClearDeviceMetricsOverride,
+		emulation.CommandSetDeviceMetricsOverride,
+	}
+	if !slices.Equal(executor.commands, want) {
+		t.Fatalf("sent %q, want %q", executor.commands, want)
+	}
+
+	// Laid out as printed, at the width of Letter less the margins
+	if media := executor.params[0].(*emulation.SetEmulatedMediaParams).Media; media != "print" {
+		t.Errorf("emulated media %q while reading headings, want print", media)
+	}
+	if width := executor.params[1].(*emulation.SetDeviceMetricsOverrideParams).Width; width != 624 {
+		t.Errorf("laid out at %dpx, want 624px", width)
+	}
+	if expression := executor.params[2].(*runtime.EvaluateParams).Expression; !strings.HasSuffix(expression, "(864)") {
+		t.Errorf("headings read with pages of %s, want 864px", expression[strings.LastIndex(expression, "("):])
+	}
+
+	// And put back as the options set it up
+	if media := executor.params[3].(*emulation.SetEmulatedMediaParams).Media; media != "" {
+		t.Errorf("emulated media %q after reading headings, want none", media)
+	}
+	if width := executor.params[5].(*emulation.SetDeviceMetricsOverrideParams).Width; width != 1280 {
+		t.Errorf("viewport put back at %dpx, want 1280px", width)
+	}
+}
+
+func TestBuildOutlineForcedBreaks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	// Short sections, each forced onto a page of its own
+	html := `<h1>One</h1><p>First</p>
+		<h1 style="break-before:page">Two</h1><p>Second</p>
+		<div style="break-after:page"><h2>Two point one</h2></div>
+		<h1>Three</h1>`
+	g := NewGenerator(WithOptions().Format(FormatLetter).options)
+	defer g.Close()
+
+	hs, err := func() ([]heading, error) {
+		tabCtx, closeTab, err := g.openTab(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		defer closeTab()
+
+		if err := g.loadHTML(html).Do(tabCtx); err != nil {
+			return nil, err
+		}
+		return g.headings(tabCtx)
+	}()
+	if errors.Is(err, ErrBrowserStart) {
+		t.Skipf("Chrome is not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("headings() error = %v", err)
+	}
+
+	var pages []int
+	for _, h := range hs {
+		pages = append(pages, h.Page)
+	}
+	if want := []int{1, 2, 2, 3}; !slices.Equal(pages, want) {
+		t.Errorf("headings on pages %v, want %v", pages, want)
+	}
+}
@@ -0,0 +1,161 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PageNumberPosition is where on the page page numbers are drawn
+type PageNumberPosition string
+
+// Page number positions
+const (
+	TopLeft      PageNumberPosition = "top-left"
+	TopCenter    PageNumberPosition = "top-center"
+	TopRight     PageNumberPosition = "top-right"
+	BottomLeft   PageNumberPosition = "bottom-left"
+	BottomCenter PageNumberPosition = "bottom-center"
+	BottomRight  PageNumberPosition = "bottom-right"
+)
+
+// PageNumberOptions describes page numbers drawn onto every page of the PDF
+// after Chrome printed it, without using Chrome's header and footer
+type PageNumberOptions struct {
+	Format   string             `json:"format,omitempty"`   // e.g. "Page %d of %d", with the page number and then the page count; "Page %d of %d" when empty
+	Position PageNumberPosition `json:"position,omitempty"` // BottomCenter when empty
+	Font     string             `json:"font,omitempty"`     // One of the watermark fonts, Helvetica when empty
+	Size     float64            `json:"size,omitempty"`     // Font size in points, 10 when zero
+	Color    string             `json:"color,omitempty"`    // Hex color such as "#333333", black when empty
+}
+
+// pageNumberMargin is the distance in points from the edges of the page to
+// the page numbers, a third of an inch
+const pageNumberMargin = 24
+
+// pageNumberFont is the resource name page numbers are drawn with
+const pageNumberFont = "HtmlgopdfPageNumberFont"
+
+// validate checks the format, position, font and color
+func (p *PageNumberOptions) validate() error {
+	// Only %d is replaced, and at most twice
+	verbs := strings.ReplaceAll(p.Format, "%%", "")
+	if n := strings.Count(verbs, "%d"); n > 2 || strings.Count(verbs, "%") != n {
+		return fmt.Errorf("invalid page number format %q: may only contain %%d for the page number and then the page count", p.Format)
+	}
+	switch p.Position {
+	case "", TopLeft, TopCenter, TopRight, BottomLeft, BottomCenter, BottomRight:
+	default:
+		return fmt.Errorf("unknown page number position %q", p.Position)
+	}
+	if _, ok := watermarkFonts[p.Font]; p.Font != "" && !ok {
+		return fmt.Errorf("unknown page number font %q: must be Helvetica, Helvetica-Bold, Courier or Courier-Bold", p.Font)
+	}
+	if _, err := parseHexColor(p.Color); p.Color != "" && err != nil {
+		return err
+	}
+	return nil
+}
+
+// text formats the page number of page out of count
+func (p *PageNumberOptions) text(page, count int) string {
+	format := p.Format
+	if format == "" {
+		format = "Page %d of %d"
+	}
+
+	args := []any{page, count}
+	return fmt.Sprintf(format, args[:strings.Count(strings.ReplaceAll(format, "%%", ""), "%d")]...)
+}
+
+// applyPageNumbers draws the page numbers set in opts onto every page of
+// data, counting from StartPageNumber
+func applyPageNumbers(data []byte, opts *PDFOptions) ([]byte, error) {
+	p := opts.PageNumbers
+	if p == nil {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add page numbers: %w", err)
+	}
+
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to add page numbers: %w", err)
+	}
+
+	font, size, position := p.Font, p.Size, p.Position
+	if font == "" {
+		font = "Helvetica"
+	}
+	if size == 0 {
+		size = 10
+	}
+	if position == "" {
+		position = BottomCenter
+	}
+	var rgb [3]float64
+	if p.Color != "" {
+		rgb, _ = parseHexColor(p.Color)
+	}
+
+	fontRef := doc.add(pdfDict{
+		"Type":     pdfName("Font"),
+		"Subtype":  pdfName("Type1"),
+		"BaseFont": pdfName(font),
+		"Encoding": pdfName("WinAnsiEncoding"),
+	})
+	save := doc.add(&pdfStream{Dict: pdfDict{}, Data: []byte("q\n")})
+	offset := opts.pageOffset()
+
+	for n, ref := range pages {
+		page := doc.dict(ref)
+
+		var content bytes.Buffer
+		content.WriteString("Q\nq\n")
+		writeTextAt(&content, winAnsi(p.text(n+1+offset, len(pages)+offset)), pageNumberFont, font, size, rgb, position, mediaBox(doc, page))
+		content.WriteString("Q\n")
+		numbers := doc.add(&pdfStream{Dict: pdfDict{}, Data: content.Bytes()})
+
+		drawOver(doc, page, save, numbers)
+
+		page["Resources"] = withResource(doc, page["Resources"], "Font", pageNumberFont, fontRef)
+	}
+
+	return doc.bytes(), nil
+}
+
+// writeTextAt writes the content drawing text in the corner or at the
+// middle of an edge of a page with the given media box. The font is
+// available to the page under the resource name.
+func writeTextAt(content *bytes.Buffer, text pdfString, name pdfName, font string, size float64, rgb [3]float64, position PageNumberPosition, rect [4]float64) {
+	var width float64
+	for _, c := range text {
+		width += float64(charWidth(font, c)) / 1000 * size
+	}
+
+	var x, y float64
+	switch position {
+	case TopLeft, BottomLeft:
+		x = rect[0] + pageNumberMargin
+	case TopCenter, BottomCenter:
+		x = (rect[0]+rect[2])/2 - width/2
+	default:
+		x = rect[2] - pageNumberMargin - width
+	}
+	switch position {
+	case TopLeft, TopCenter, TopRight:
+		// The cap height is roughly 0.7 of the size
+		y = rect[3] - pageNumberMargin - size*0.7
+	default:
+		y = rect[1] + pageNumberMargin
+	}
+
+	fmt.Fprintf(content, "%s %s %s rg\nBT\n/%s %s Tf\n%s %s Td\n",
+		formatReal(rgb[0]), formatReal(rgb[1]), formatReal(rgb[2]), name, formatReal(size),
+		formatReal(x), formatReal(y))
+	writeString(content, text)
+	content.WriteString(" Tj\nET\n")
+}
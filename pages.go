@@ -0,0 +1,56 @@
+package htmlgopdf
+
+import (
+	"fmt"
+)
+
+// PageInput is one part of a multi-part document: its HTML and, optionally,
+// the options to render it with instead of the generator's own
+type PageInput struct {
+	HTML    string
+	Options *PDFOptions
+}
+
+// FromHTMLSlice renders each HTML string as its own group of pages and
+// combines them, in order, into a single PDF
+func (g *Generator) FromHTMLSlice(pages []string) ([]byte, error) {
+	inputs := make([]PageInput, len(pages))
+	for i, htmlContent := range pages {
+		inputs[i] = PageInput{HTML: htmlContent}
+	}
+
+	return g.FromPageInputs(inputs)
+}
+
+// FromPageInputs renders each input as its own group of pages and combines
+// them, in order, into a single PDF. Every part is rendered on the same
+// browser, so browser-level settings such as ChromePath come from the
+// generator's options.
+func (g *Generator) FromPageInputs(inputs []PageInput) ([]byte, error) {
+	b := g.browser
+	if b == nil {
+		b = newBrowser(g.options)
+		defer b.close()
+	}
+
+	pdfs := make([][]byte, 0, len(inputs))
+	for i, input := range inputs {
+		part := &Generator{options: g.options, browser: b}
+		if input.Options != nil {
+			part.options = input.Options
+		}
+
+		pdfData, err := part.FromHTML(input.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate part %d: %w", i, err)
+		}
+		pdfs = append(pdfs, pdfData)
+	}
+
+	merged, err := mergePDFs(pdfs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge PDFs: %w", err)
+	}
+
+	return merged, nil
+}
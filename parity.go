@@ -0,0 +1,112 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// HeaderFooterParityOptions describes plain text headers and footers that
+// differ between odd and even pages, as in books printed on both sides.
+// They are drawn onto the PDF after Chrome printed it, against the outer
+// edge: on the right of odd pages and on the left of even ones.
+type HeaderFooterParityOptions struct {
+	OddHeader  string  `json:"oddHeader,omitempty"`  // e.g. the chapter title, on recto pages
+	EvenHeader string  `json:"evenHeader,omitempty"` // e.g. the book title, on verso pages
+	OddFooter  string  `json:"oddFooter,omitempty"`
+	EvenFooter string  `json:"evenFooter,omitempty"`
+	Font       string  `json:"font,omitempty"`  // One of the watermark fonts, Helvetica when empty
+	Size       float64 `json:"size,omitempty"`  // Font size in points, 9 when zero
+	Color      string  `json:"color,omitempty"` // Hex color such as "#333333", black when empty
+}
+
+// parityFont is the resource name parity headers and footers are drawn with
+const parityFont = "HtmlgopdfHeaderFooterFont"
+
+// validate checks the font and color
+func (p *HeaderFooterParityOptions) validate() error {
+	if _, ok := watermarkFonts[p.Font]; p.Font != "" && !ok {
+		return fmt.Errorf("unknown header font %q: must be Helvetica, Helvetica-Bold, Courier or Courier-Bold", p.Font)
+	}
+	if _, err := parseHexColor(p.Color); p.Color != "" && err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyHeaderFooterParity draws the odd and even headers and footers set in
+// opts onto the pages of data, by their page number counting from
+// StartPageNumber. The first page is left alone with
+// SkipFirstPageHeaderFooter.
+func applyHeaderFooterParity(data []byte, opts *PDFOptions) ([]byte, error) {
+	p := opts.HeaderFooterParity
+	if p == nil {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add headers and footers: %w", err)
+	}
+
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to add headers and footers: %w", err)
+	}
+
+	font, size := p.Font, p.Size
+	if font == "" {
+		font = "Helvetica"
+	}
+	if size == 0 {
+		size = 9
+	}
+	var rgb [3]float64
+	if p.Color != "" {
+		rgb, _ = parseHexColor(p.Color)
+	}
+
+	fontRef := doc.add(pdfDict{
+		"Type":     pdfName("Font"),
+		"Subtype":  pdfName("Type1"),
+		"BaseFont": pdfName(font),
+		"Encoding": pdfName("WinAnsiEncoding"),
+	})
+	save := doc.add(&pdfStream{Dict: pdfDict{}, Data: []byte("q\n")})
+
+	for i, ref := range pages {
+		number := i + 1 + opts.pageOffset()
+		if i == 0 && opts.SkipFirstPageHeaderFooter {
+			continue
+		}
+
+		header, footer := p.OddHeader, p.OddFooter
+		top, bottom := TopRight, BottomRight
+		if number%2 == 0 {
+			header, footer = p.EvenHeader, p.EvenFooter
+			top, bottom = TopLeft, BottomLeft
+		}
+		if header == "" && footer == "" {
+			continue
+		}
+
+		page := doc.dict(ref)
+		rect := mediaBox(doc, page)
+
+		var content bytes.Buffer
+		content.WriteString("Q\nq\n")
+		if header != "" {
+			writeTextAt(&content, winAnsi(header), parityFont, font, size, rgb, top, rect)
+		}
+		if footer != "" {
+			writeTextAt(&content, winAnsi(footer), parityFont, font, size, rgb, bottom, rect)
+		}
+		content.WriteString("Q\n")
+		overlay := doc.add(&pdfStream{Dict: pdfDict{}, Data: content.Bytes()})
+
+		drawOver(doc, page, save, overlay)
+
+		page["Resources"] = withResource(doc, page["Resources"], "Font", parityFont, fontRef)
+	}
+
+	return doc.bytes(), nil
+}
@@ -0,0 +1,917 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// This file holds a minimal PDF object model: enough to read the documents
+// Chrome produces, and unencrypted ones from other tools, rearrange their
+// objects and write them back out. It is not a general purpose PDF library.
+
+// pdfObject is any PDF value: nil, bool, int64, float64, pdfName,
+// pdfString, pdfArray, pdfDict, pdfRef or *pdfStream
+type pdfObject interface{}
+
+// pdfName is a PDF name such as /Type, stored without the slash
+type pdfName string
+
+// pdfString is a PDF string, stored as raw bytes
+type pdfString []byte
+
+// pdfArray is a PDF array
+type pdfArray []pdfObject
+
+// pdfDict is a PDF dictionary
+type pdfDict map[pdfName]pdfObject
+
+// pdfRef is an indirect reference such as 12 0 R
+type pdfRef struct {
+	Num int
+	Gen int
+}
+
+// pdfStream is a stream object with its raw, still encoded data
+type pdfStream struct {
+	Dict pdfDict
+	Data []byte
+}
+
+// pdfDocument is a parsed PDF held in memory
+type pdfDocument struct {
+	version string
+	objects map[int]pdfObject
+	trailer pdfDict
+	maxNum  int
+}
+
+// errInvalidPDF is returned when a document can't be parsed
+var errInvalidPDF = errors.New("invalid PDF")
+
+// objectHeader matches the start of an indirect object definition
+var objectHeader = regexp.MustCompile(`(?m)(?:^|\s)(\d+)\s+(\d+)\s+obj\b`)
+
+// trailerHeader matches the start of a trailer dictionary
+var trailerHeader = regexp.MustCompile(`trailer\s*<<`)
+
+// newPDFDocument creates an empty document
+func newPDFDocument() *pdfDocument {
+	return &pdfDocument{
+		version: "1.4",
+		objects: make(map[int]pdfObject),
+		trailer: pdfDict{},
+	}
+}
+
+// parsePDF reads every indirect object in data. Objects are located by
+// scanning for "N G obj", so later definitions of the same object number
+// win, as they would with incremental updates.
+func parsePDF(data []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing header", errInvalidPDF)
+	}
+
+	doc := newPDFDocument()
+	if end := bytes.IndexAny(data[5:], "\r\n"); end > 0 {
+		doc.version = string(data[5 : 5+end])
+	}
+
+	// Stream lengths may refer to objects defined later in the file, so
+	// remember where streams start and parse those again once all plain
+	// objects are known
+	starts := make(map[int]int)
+	end := 0
+	for _, loc := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		if loc[2] < end {
+			// A match inside the data of the previous object
+			continue
+		}
+
+		num, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		p := &pdfParser{data: data, pos: loc[1], doc: doc}
+		obj, err := p.parseIndirect()
+		if err != nil {
+			return nil, fmt.Errorf("%w: object %d: %v", errInvalidPDF, num, err)
+		}
+
+		doc.set(num, obj)
+		starts[num] = loc[1]
+		end = p.pos
+	}
+	for num, start := range starts {
+		if stream, ok := doc.objects[num].(*pdfStream); ok {
+			if _, isRef := stream.Dict["Length"].(pdfRef); isRef {
+				p := &pdfParser{data: data, pos: start, doc: doc}
+				if obj, err := p.parseIndirect(); err == nil {
+					doc.objects[num] = obj
+				}
+			}
+		}
+	}
+
+	// Files from PDF 1.5 on may keep objects compressed in object streams
+	// and the trailer in cross-reference streams
+	type trailerAt struct {
+		offset int
+		dict   pdfDict
+	}
+	var trailers []trailerAt
+	for num, start := range starts {
+		stream, ok := doc.objects[num].(*pdfStream)
+		if !ok {
+			continue
+		}
+		switch stream.Dict["Type"] {
+		case pdfName("XRef"):
+			trailers = append(trailers, trailerAt{start, stream.Dict})
+			delete(doc.objects, num)
+		case pdfName("ObjStm"):
+			if err := doc.unpackObjectStream(stream); err != nil {
+				return nil, fmt.Errorf("%w: object stream %d: %v", errInvalidPDF, num, err)
+			}
+			delete(doc.objects, num)
+		}
+	}
+
+	for _, loc := range trailerHeader.FindAllIndex(data, -1) {
+		p := &pdfParser{data: data, pos: loc[0] + len("trailer"), doc: doc}
+		if obj, err := p.parseObject(); err == nil {
+			if dict, ok := obj.(pdfDict); ok {
+				trailers = append(trailers, trailerAt{loc[0], dict})
+			}
+		}
+	}
+
+	// Merge every trailer, later ones taking precedence
+	sort.Slice(trailers, func(i, j int) bool { return trailers[i].offset < trailers[j].offset })
+	for _, t := range trailers {
+		for k, v := range t.dict {
+			doc.trailer[k] = v
+		}
+	}
+
+	if _, ok := doc.trailer["Root"]; !ok {
+		return nil, fmt.Errorf("%w: missing document catalog", errInvalidPDF)
+	}
+
+	return doc, nil
+}
+
+// unpackObjectStream adds the objects compressed in an object stream,
+// unless they are also defined on their own, which happens when a later
+// update of the file changed them
+func (d *pdfDocument) unpackObjectStream(stream *pdfStream) error {
+	if stream.Dict["Filter"] != nil && stream.Dict["Filter"] != pdfName("FlateDecode") {
+		return fmt.Errorf("unsupported filter %v", stream.Dict["Filter"])
+	}
+
+	data := stream.Data
+	if stream.Dict["Filter"] != nil {
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return err
+		}
+	}
+
+	n, _ := stream.Dict["N"].(int64)
+	first, _ := stream.Dict["First"].(int64)
+	if first < 0 || int(first) > len(data) {
+		return errors.New("invalid offset of the first object")
+	}
+
+	// The stream starts with pairs of object numbers and offsets from First
+	p := &pdfParser{data: data}
+	for range n {
+		num, err := p.parseObject()
+		if err != nil {
+			return err
+		}
+		offset, err := p.parseObject()
+		if err != nil {
+			return err
+		}
+		numInt, ok1 := num.(int64)
+		offsetInt, ok2 := offset.(int64)
+		if !ok1 || !ok2 || numInt < 0 || offsetInt < 0 || offsetInt > int64(len(data))-first {
+			return errors.New("invalid object header")
+		}
+
+		if _, defined := d.objects[int(numInt)]; defined {
+			continue
+		}
+		obj, err := (&pdfParser{data: data, pos: int(first + offsetInt), doc: d}).parseObject()
+		if err != nil {
+			return fmt.Errorf("object %d: %w", numInt, err)
+		}
+		d.set(int(numInt), obj)
+	}
+	return nil
+}
+
+// resolve follows obj if it is a reference
+func (d *pdfDocument) resolve(obj pdfObject) pdfObject {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[ref.Num]
+	}
+	return nil
+}
+
+// dict resolves obj and returns it as a dictionary, using the dictionary of
+// a stream if needed
+func (d *pdfDocument) dict(obj pdfObject) pdfDict {
+	switch v := d.resolve(obj).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.Dict
+	}
+	return nil
+}
+
+// catalog returns the document catalog
+func (d *pdfDocument) catalog() pdfDict {
+	return d.dict(d.trailer["Root"])
+}
+
+// set stores obj under the given object number
+func (d *pdfDocument) set(num int, obj pdfObject) {
+	d.objects[num] = obj
+	if num > d.maxNum {
+		d.maxNum = num
+	}
+}
+
+// add stores obj as a new indirect object and returns its reference
+func (d *pdfDocument) add(obj pdfObject) pdfRef {
+	ref := pdfRef{Num: d.maxNum + 1}
+	d.set(ref.Num, obj)
+	return ref
+}
+
+// pages returns the page objects in document order, with inheritable
+// attributes copied down from the page tree onto each page
+func (d *pdfDocument) pages() ([]pdfRef, error) {
+	root, ok := d.catalog()["Pages"].(pdfRef)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing page tree", errInvalidPDF)
+	}
+
+	var pages []pdfRef
+	visited := make(map[int]bool)
+
+	var walk func(ref pdfRef, inherited pdfDict) error
+	walk = func(ref pdfRef, inherited pdfDict) error {
+		if visited[ref.Num] {
+			return fmt.Errorf("%w: page tree cycle at object %d", errInvalidPDF, ref.Num)
+		}
+		visited[ref.Num] = true
+
+		node := d.dict(ref)
+		if node == nil {
+			return fmt.Errorf("%w: missing page tree node %d", errInvalidPDF, ref.Num)
+		}
+
+		if node["Type"] == pdfName("Page") || node["Kids"] == nil {
+			for k, v := range inherited {
+				if _, ok := node[k]; !ok {
+					node[k] = v
+				}
+			}
+			pages = append(pages, ref)
+			return nil
+		}
+
+		next := pdfDict{}
+		for k, v := range inherited {
+			next[k] = v
+		}
+		for _, k := range []pdfName{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if v, ok := node[k]; ok {
+				next[k] = v
+			}
+		}
+
+		kids, _ := d.resolve(node["Kids"]).(pdfArray)
+		for _, kid := range kids {
+			kidRef, ok := kid.(pdfRef)
+			if !ok {
+				return fmt.Errorf("%w: page tree kid is not a reference", errInvalidPDF)
+			}
+			if err := walk(kidRef, next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root, pdfDict{}); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// importObject deep-copies obj from src into d, renumbering references.
+// mapping tracks objects already copied so shared resources stay shared.
+// Keys listed in skip are dropped from dictionaries at the top level.
+func (d *pdfDocument) importObject(src *pdfDocument, obj pdfObject, mapping map[int]pdfRef, skip ...pdfName) pdfObject {
+	switch v := obj.(type) {
+	case pdfRef:
+		if ref, ok := mapping[v.Num]; ok {
+			return ref
+		}
+		target, ok := src.objects[v.Num]
+		if !ok {
+			return nil
+		}
+		// Reserve the number before recursing, so cycles terminate
+		ref := d.add(nil)
+		mapping[v.Num] = ref
+		d.objects[ref.Num] = d.importObject(src, target, mapping)
+		return ref
+	case pdfDict:
+		out := make(pdfDict, len(v))
+		for k, item := range v {
+			if containsName(skip, k) {
+				continue
+			}
+			out[k] = d.importObject(src, item, mapping)
+		}
+		return out
+	case pdfArray:
+		out := make(pdfArray, len(v))
+		for i, item := range v {
+			out[i] = d.importObject(src, item, mapping)
+		}
+		return out
+	case *pdfStream:
+		return &pdfStream{
+			// Length is recomputed when writing
+			Dict: d.importObject(src, v.Dict, mapping, "Length").(pdfDict),
+			Data: v.Data,
+		}
+	}
+	return obj
+}
+
+// containsName reports whether names contains name
+func containsName(names []pdfName, name pdfName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bytes serializes the document with a fresh cross-reference table
+func (d *pdfDocument) bytes() []byte {
+	var buf bytes.Buffer
+	d.writeTo(&buf)
+	return buf.Bytes()
+}
+
+// writeTo serializes the document to w
+func (d *pdfDocument) writeTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%%PDF-%s\n%%\xe2\xe3\xcf\xd3\n", d.version)
+
+	nums := make([]int, 0, len(d.objects))
+	size := 1
+	for n := range d.objects {
+		nums = append(nums, n)
+		if n+1 > size {
+			size = n + 1
+		}
+	}
+	sort.Ints(nums)
+
+	offsets := make(map[int]int, len(nums))
+	for _, n := range nums {
+		offsets[n] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", n)
+		writeObject(&buf, d.objects[n])
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for n := 1; n < size; n++ {
+		if off, ok := offsets[n]; ok {
+			fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+		} else {
+			buf.WriteString("0000000000 65535 f \n")
+		}
+	}
+
+	trailer := pdfDict{}
+	for k, v := range d.trailer {
+		switch k {
+		case "Prev", "XRefStm", "Type", "Filter", "DecodeParms", "Length", "Index", "W":
+			// Only meaningful for the original file layout
+		default:
+			trailer[k] = v
+		}
+	}
+	trailer["Size"] = int64(size)
+
+	buf.WriteString("trailer\n")
+	writeObject(&buf, trailer)
+	fmt.Fprintf(&buf, "\nstartxref\n%d\n%%%%EOF\n", xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// writeObject serializes a single PDF value
+func writeObject(buf *bytes.Buffer, obj pdfObject) {
+	switch v := obj.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		buf.WriteString(strconv.Itoa(v))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		buf.WriteString(formatReal(v))
+	case pdfName:
+		writeName(buf, v)
+	case pdfString:
+		writeString(buf, v)
+	case pdfRef:
+		fmt.Fprintf(buf, "%d %d R", v.Num, v.Gen)
+	case pdfArray:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writeObject(buf, item)
+		}
+		buf.WriteByte(']')
+	case pdfDict:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, string(k))
+		}
+		sort.Strings(keys)
+
+		buf.WriteString("<<")
+		for _, k := range keys {
+			writeName(buf, pdfName(k))
+			buf.WriteByte(' ')
+			writeObject(buf, v[pdfName(k)])
+		}
+		buf.WriteString(">>")
+	case *pdfStream:
+		dict := make(pdfDict, len(v.Dict)+1)
+		for k, item := range v.Dict {
+			dict[k] = item
+		}
+		dict["Length"] = int64(len(v.Data))
+
+		writeObject(buf, dict)
+		buf.WriteString("\nstream\n")
+		buf.Write(v.Data)
+		buf.WriteString("\nendstream")
+	default:
+		panic(fmt.Sprintf("htmlgopdf: cannot serialize %T", obj))
+	}
+}
+
+// formatReal formats a number without exponent notation, which PDF lacks
+func formatReal(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 5, 64)
+	s = trimRight(s, '0')
+	return trimRight(s, '.')
+}
+
+// trimRight removes trailing occurrences of c, leaving at least one byte
+func trimRight(s string, c byte) string {
+	for len(s) > 1 && s[len(s)-1] == c {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// writeName writes a name, escaping bytes that aren't regular characters
+func writeName(buf *bytes.Buffer, name pdfName) {
+	buf.WriteByte('/')
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < '!' || c > '~' || c == '#' || isDelimiter(c) {
+			fmt.Fprintf(buf, "#%02X", c)
+		} else {
+			buf.WriteByte(c)
+		}
+	}
+}
+
+// writeString writes a literal string, escaping what needs escaping
+func writeString(buf *bytes.Buffer, s pdfString) {
+	buf.WriteByte('(')
+	for _, c := range s {
+		switch c {
+		case '(', ')', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\n':
+			buf.WriteString(`\n`)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+}
+
+// pdfParser reads PDF values from a byte slice
+type pdfParser struct {
+	data []byte
+	pos  int
+	doc  *pdfDocument // used to resolve indirect stream lengths
+}
+
+// isWhitespace reports whether c is PDF whitespace
+func isWhitespace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+// isDelimiter reports whether c is a PDF delimiter
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace and comments
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isWhitespace(c) {
+			p.pos++
+		} else if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// keyword reads a run of regular characters
+func (p *pdfParser) keyword() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isWhitespace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// parseIndirect parses the body of an indirect object, after "N G obj"
+func (p *pdfParser) parseIndirect() (pdfObject, error) {
+	obj, err := p.parseObject()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	start := p.pos
+	if p.keyword() != "stream" {
+		p.pos = start
+		return obj, nil
+	}
+
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return nil, errors.New("stream without dictionary")
+	}
+
+	// The data starts after the end of line following the keyword
+	if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+	}
+
+	length := -1
+	switch v := dict["Length"].(type) {
+	case int64:
+		length = int(v)
+	case pdfRef:
+		if p.doc != nil {
+			if n, ok := p.doc.objects[v.Num].(int64); ok {
+				length = int(n)
+			}
+		}
+	}
+
+	if length > len(p.data)-p.pos {
+		// Checked before adding, which would overflow for a huge Length
+		return nil, fmt.Errorf("stream length %d exceeds the data", length)
+	}
+
+	end := p.pos + length
+	if length < 0 || !bytes.HasPrefix(bytes.TrimLeft(p.data[end:], "\r\n "), []byte("endstream")) {
+		// Length is missing or wrong, fall back to searching for the end
+		idx := bytes.Index(p.data[p.pos:], []byte("endstream"))
+		if idx < 0 {
+			return nil, errors.New("unterminated stream")
+		}
+		end = p.pos + idx
+		for end > p.pos && (p.data[end-1] == '\n' || p.data[end-1] == '\r') {
+			end--
+		}
+	}
+
+	stream := &pdfStream{Dict: dict, Data: p.data[p.pos:end]}
+	p.pos = end
+	return stream, nil
+}
+
+// parseObject parses a single PDF value
+func (p *pdfParser) parseObject() (pdfObject, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '/':
+		p.pos++
+		return p.parseName(), nil
+	case c == '(':
+		return p.parseLiteralString()
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		return p.parseDict()
+	case c == '<':
+		return p.parseHexString()
+	case c == '[':
+		return p.parseArray()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumberOrRef()
+	}
+
+	word := p.keyword()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.data[p.pos], p.pos)
+	}
+	return nil, fmt.Errorf("unexpected keyword %q at offset %d", word, p.pos)
+}
+
+// parseName parses a name after its slash, decoding #xx escapes
+func (p *pdfParser) parseName() pdfName {
+	raw := p.keyword()
+	if !bytes.ContainsRune([]byte(raw), '#') {
+		return pdfName(raw)
+	}
+
+	var out []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if b, err := strconv.ParseUint(raw[i+1:i+3], 16, 8); err == nil {
+				out = append(out, byte(b))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return pdfName(out)
+}
+
+// parseNumberOrRef parses a number, or a reference when it is followed by
+// a generation number and R
+func (p *pdfParser) parseNumberOrRef() (pdfObject, error) {
+	num, isInt, err := p.parseNumber()
+	if err != nil {
+		return nil, err
+	}
+	if !isInt {
+		return num, nil
+	}
+
+	// Look ahead for "G R"
+	save := p.pos
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		gen, genIsInt, err := p.parseNumber()
+		if err == nil && genIsInt {
+			p.skipSpace()
+			if p.pos < len(p.data) && p.data[p.pos] == 'R' &&
+				(p.pos+1 == len(p.data) || isWhitespace(p.data[p.pos+1]) || isDelimiter(p.data[p.pos+1])) {
+				p.pos++
+				return pdfRef{Num: int(num.(int64)), Gen: int(gen.(int64))}, nil
+			}
+		}
+	}
+	p.pos = save
+
+	return num, nil
+}
+
+// parseNumber parses an integer or real number
+func (p *pdfParser) parseNumber() (pdfObject, bool, error) {
+	start := p.pos
+	if p.data[p.pos] == '+' || p.data[p.pos] == '-' {
+		p.pos++
+	}
+	isInt := true
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '.' {
+			isInt = false
+		} else if c < '0' || c > '9' {
+			break
+		}
+		p.pos++
+	}
+
+	text := string(p.data[start:p.pos])
+	if isInt {
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid number %q", text)
+		}
+		return n, true, nil
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		if text == "-" || text == "+" || text == "." {
+			return float64(0), false, nil
+		}
+		return nil, false, fmt.Errorf("invalid number %q", text)
+	}
+	return f, false, nil
+}
+
+// parseLiteralString parses a (string), handling escapes and nesting
+func (p *pdfParser) parseLiteralString() (pdfObject, error) {
+	p.pos++ // (
+	var out []byte
+	depth := 1
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(out), nil
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				// Line continuation
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+
+	return nil, errors.New("unterminated string")
+}
+
+// parseHexString parses a <hex string>
+func (p *pdfParser) parseHexString() (pdfObject, error) {
+	p.pos++ // <
+	var digits []byte
+	for p.pos < len(p.data) && p.data[p.pos] != '>' {
+		if c := p.data[p.pos]; !isWhitespace(c) {
+			digits = append(digits, c)
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		return nil, errors.New("unterminated hex string")
+	}
+	p.pos++ // >
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		b, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex string")
+		}
+		out[i] = byte(b)
+	}
+	return pdfString(out), nil
+}
+
+// parseArray parses a [array]
+func (p *pdfParser) parseArray() (pdfObject, error) {
+	p.pos++ // [
+	arr := pdfArray{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, errors.New("unterminated array")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return arr, nil
+		}
+		item, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, item)
+	}
+}
+
+// parseDict parses a <<dictionary>>
+func (p *pdfParser) parseDict() (pdfObject, error) {
+	p.pos += 2 // <<
+	dict := pdfDict{}
+	for {
+		p.skipSpace()
+		if p.pos+1 >= len(p.data) {
+			return nil, errors.New("unterminated dictionary")
+		}
+		if p.data[p.pos] == '>' && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return dict, nil
+		}
+		if p.data[p.pos] != '/' {
+			return nil, fmt.Errorf("dictionary key is not a name at offset %d", p.pos)
+		}
+		p.pos++
+		key := p.parseName()
+
+		value, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		dict[key] = value
+	}
+}
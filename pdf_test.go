@@ -0,0 +1,43 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParsePDFHugeStreamLength(t *testing.T) {
+	data := []byte("%PDF-1.4\n" +
+		"1 0 obj\n<< /Length 9223372036854775800 >>\nstream\nabc\nendstream\nendobj\n" +
+		"trailer\n<< /Root 1 0 R >>\n")
+
+	if _, err := parsePDF(data); !errors.Is(err, errInvalidPDF) {
+		t.Fatalf("parsePDF() error = %v, want errInvalidPDF", err)
+	}
+}
+
+func TestParsePDFInvalidObjectStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  string
+		header string
+	}{
+		{"negative offset", "7", "2 -20\n"},
+		{"negative first", "-30", "2 0\n"},
+		{"offset past the end", "5", "2 500\n"},
+		{"negative object number", "6", "-2 0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := tt.header + "<< /Type /Catalog >>\n"
+			data := []byte(fmt.Sprintf("%%PDF-1.5\n"+
+				"1 0 obj\n<< /Type /ObjStm /N 1 /First %s /Length %d >>\nstream\n%s\nendstream\nendobj\n"+
+				"trailer\n<< /Root 2 0 R >>\n", tt.first, len(content), content))
+
+			if _, err := parsePDF(data); !errors.Is(err, errInvalidPDF) {
+				t.Fatalf("parsePDF() error = %v, want errInvalidPDF", err)
+			}
+		})
+	}
+}
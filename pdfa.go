@@ -0,0 +1,225 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf16"
+)
+
+// PDF/A conformance levels accepted by Archival
+const (
+	PDFA1b = "PDF/A-1b"
+	PDFA2b = "PDF/A-2b"
+)
+
+// ErrPDFACompliance is returned when the PDF Chrome produced can't be made
+// to conform to the requested PDF/A level, e.g. because a font isn't embedded
+var ErrPDFACompliance = errors.New("PDF/A compliance check failed")
+
+// pdfaParts are the ISO 19005 part and PDF version of each level
+var pdfaParts = map[string]struct {
+	part    int
+	version string
+}{
+	PDFA1b: {1, "1.4"},
+	PDFA2b: {2, "1.7"},
+}
+
+// applyArchival turns data into a PDF/A document of the level set in opts:
+// it checks that fonts are embedded, and for PDF/A-1b that nothing is
+// transparent, then adds the sRGB output intent, the XMP metadata declaring
+// the level and a document ID
+func applyArchival(data []byte, opts *PDFOptions) ([]byte, error) {
+	level, ok := pdfaParts[opts.Archival]
+	if !ok {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to %s: %w", opts.Archival, err)
+	}
+
+	if err := checkArchival(doc, level.part); err != nil {
+		return nil, fmt.Errorf("%w for %s: %w", ErrPDFACompliance, opts.Archival, err)
+	}
+
+	catalog := doc.catalog()
+	profile := doc.add(&pdfStream{Dict: pdfDict{"N": int64(3)}, Data: srgbProfile()})
+	catalog["OutputIntents"] = pdfArray{pdfDict{
+		"Type":                      pdfName("OutputIntent"),
+		"S":                         pdfName("GTS_PDFA1"),
+		"OutputConditionIdentifier": pdfString("sRGB IEC61966-2.1"),
+		"Info":                      pdfString("sRGB IEC61966-2.1"),
+		"DestOutputProfile":         profile,
+	}}
+
+	info := doc.dict(doc.trailer["Info"])
+	catalog["Metadata"] = doc.add(&pdfStream{
+		Dict: pdfDict{"Type": pdfName("Metadata"), "Subtype": pdfName("XML")},
+		Data: xmpPacket(info, level.part),
+	})
+
+	if _, ok := doc.trailer["ID"]; !ok {
+		id := md5.Sum(data)
+		doc.trailer["ID"] = pdfArray{pdfString(id[:]), pdfString(id[:])}
+	}
+	doc.version = level.version
+
+	return doc.bytes(), nil
+}
+
+// checkArchival reports the first thing in doc PDF/A doesn't allow
+func checkArchival(doc *pdfDocument, part int) error {
+	for num, obj := range doc.objects {
+		dict := doc.dict(obj)
+		if dict == nil {
+			continue
+		}
+
+		if dict["Type"] == pdfName("FontDescriptor") &&
+			dict["FontFile"] == nil && dict["FontFile2"] == nil && dict["FontFile3"] == nil {
+			return fmt.Errorf("font %s is not embedded", fontName(dict))
+		}
+
+		// Standard fonts have no descriptor, and are never embedded
+		if dict["Type"] == pdfName("Font") && dict["FontDescriptor"] == nil {
+			switch dict["Subtype"] {
+			case pdfName("Type1"), pdfName("TrueType"), pdfName("MMType1"):
+				return fmt.Errorf("font %s is not embedded", fontName(dict))
+			}
+		}
+
+		if part == 1 && transparent(dict) {
+			return fmt.Errorf("object %d uses transparency, which PDF/A-1 doesn't allow", num)
+		}
+	}
+
+	return nil
+}
+
+// fontName returns the name of a font or font descriptor
+func fontName(dict pdfDict) string {
+	for _, key := range []pdfName{"FontName", "BaseFont"} {
+		if name, ok := dict[key].(pdfName); ok {
+			return string(name)
+		}
+	}
+	return "(unnamed)"
+}
+
+// transparent reports whether dict sets up transparency: a soft mask, an
+// alpha below 1 or a transparency group
+func transparent(dict pdfDict) bool {
+	if mask, ok := dict["SMask"]; ok && mask != pdfName("None") {
+		return true
+	}
+	for _, key := range []pdfName{"CA", "ca"} {
+		switch alpha := dict[key].(type) {
+		case int64:
+			if alpha < 1 {
+				return true
+			}
+		case float64:
+			if alpha < 1 {
+				return true
+			}
+		}
+	}
+	if group, ok := dict["Group"].(pdfDict); ok && group["S"] == pdfName("Transparency") {
+		return true
+	}
+	return false
+}
+
+// pdfDate matches a PDF date such as D:20240131120000+01'00'
+var pdfDate = regexp.MustCompile(`^D:(\d{4})(\d{2})(\d{2})(\d{2})(\d{2})(\d{2})(?:(Z)|([+-])(\d{2})'(\d{2})'?)?`)
+
+// xmpDate converts a PDF date into the format XMP uses, or returns false
+func xmpDate(obj pdfObject) (string, bool) {
+	s, ok := obj.(pdfString)
+	if !ok {
+		return "", false
+	}
+
+	m := pdfDate.FindStringSubmatch(string(s))
+	if m == nil {
+		return "", false
+	}
+
+	date := fmt.Sprintf("%s-%s-%sT%s:%s:%s", m[1], m[2], m[3], m[4], m[5], m[6])
+	switch {
+	case m[7] == "Z":
+		date += "Z"
+	case m[8] != "":
+		date += m[8] + m[9] + ":" + m[10]
+	}
+	return date, true
+}
+
+// xmpPacket builds the XMP metadata of a PDF/A document of the given part,
+// repeating the entries of info as PDF/A requires them to match
+func xmpPacket(info pdfDict, part int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	buf.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + "\n")
+	buf.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` + "\n")
+	buf.WriteString(`<rdf:Description rdf:about=""` +
+		` xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/"` +
+		` xmlns:dc="http://purl.org/dc/elements/1.1/"` +
+		` xmlns:xmp="http://ns.adobe.com/xap/1.0/"` +
+		` xmlns:pdf="http://ns.adobe.com/pdf/1.3/">` + "\n")
+	fmt.Fprintf(&buf, "<pdfaid:part>%d</pdfaid:part>\n<pdfaid:conformance>B</pdfaid:conformance>\n", part)
+
+	element := func(format string, key pdfName) {
+		if s, ok := info[key].(pdfString); ok {
+			var text bytes.Buffer
+			xml.EscapeText(&text, []byte(decodeText(s)))
+			fmt.Fprintf(&buf, format+"\n", text.String())
+		}
+	}
+	element(`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">%s</rdf:li></rdf:Alt></dc:title>`, "Title")
+	element(`<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>`, "Author")
+	element(`<dc:description><rdf:Alt><rdf:li xml:lang="x-default">%s</rdf:li></rdf:Alt></dc:description>`, "Subject")
+	element(`<pdf:Keywords>%s</pdf:Keywords>`, "Keywords")
+	element(`<pdf:Producer>%s</pdf:Producer>`, "Producer")
+	element(`<xmp:CreatorTool>%s</xmp:CreatorTool>`, "Creator")
+
+	dates := []struct {
+		key  pdfName
+		name string
+	}{{"CreationDate", "xmp:CreateDate"}, {"ModDate", "xmp:ModifyDate"}}
+	for _, d := range dates {
+		if date, ok := xmpDate(info[d.key]); ok {
+			fmt.Fprintf(&buf, "<%s>%s</%s>\n", d.name, date, d.name)
+		} else {
+			// A date that can't be repeated in XMP would not match
+			delete(info, d.key)
+		}
+	}
+
+	buf.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n<?xpacket end=\"w\"?>")
+	return buf.Bytes()
+}
+
+// decodeText decodes a PDF text string, either UTF-16BE with a byte order
+// mark or, near enough, Latin-1
+func decodeText(s pdfString) string {
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, len(s)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	runes := make([]rune, len(s))
+	for i, c := range s {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
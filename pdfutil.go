@@ -0,0 +1,151 @@
+package htmlgopdf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Merge combines several PDFs into one with their pages in order, e.g.
+// sections of a report rendered by separate calls. It works on PDFs from
+// other sources too, including ones with compressed object and
+// cross-reference streams, but not on encrypted ones. Document-level data
+// such as outlines, metadata and form fields is not carried over.
+func Merge(pdfs [][]byte) ([]byte, error) {
+	if len(pdfs) == 0 {
+		return nil, errors.New("failed to merge PDFs: no PDFs given")
+	}
+
+	merged, err := mergePDFs(pdfs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge PDFs: %w", err)
+	}
+	return merged, nil
+}
+
+// PageCount returns the number of pages of pdf as recorded in its page
+// tree, e.g. to check a render produced as many pages as expected
+func PageCount(pdf []byte) (int, error) {
+	doc, err := parsePDF(pdf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pages: %w", err)
+	}
+
+	root := doc.dict(doc.catalog()["Pages"])
+	if root == nil {
+		return 0, fmt.Errorf("failed to count pages: %w: missing page tree", errInvalidPDF)
+	}
+
+	count, ok := doc.resolve(root["Count"]).(int64)
+	if !ok || count < 0 {
+		return 0, fmt.Errorf("failed to count pages: %w: invalid page count", errInvalidPDF)
+	}
+	return int(count), nil
+}
+
+// mergePDFs concatenates the pages of several PDFs, in order, into one
+// document. Document-level data such as outlines is not carried over.
+func mergePDFs(pdfs [][]byte) ([]byte, error) {
+	out := newPDFDocument()
+	pagesRef := out.add(nil)
+	var kids pdfArray
+
+	for i, data := range pdfs {
+		src, err := parsePDF(data)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if src.trailer["Encrypt"] != nil {
+			return nil, fmt.Errorf("document %d is encrypted", i)
+		}
+		if src.version > out.version {
+			out.version = src.version
+		}
+
+		pages, err := src.pages()
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+
+		// Number every page up front, so links between pages map onto
+		// the new pages instead of dragging in the old page tree
+		mapping := make(map[int]pdfRef)
+		for _, page := range pages {
+			mapping[page.Num] = out.add(nil)
+		}
+
+		for _, page := range pages {
+			ref := mapping[page.Num]
+			dict := out.importObject(src, src.dict(page), mapping, "Parent").(pdfDict)
+			dict["Parent"] = pagesRef
+			out.objects[ref.Num] = dict
+			kids = append(kids, ref)
+		}
+	}
+
+	out.objects[pagesRef.Num] = pdfDict{
+		"Type":  pdfName("Pages"),
+		"Kids":  kids,
+		"Count": int64(len(kids)),
+	}
+	out.trailer["Root"] = out.add(pdfDict{
+		"Type":  pdfName("Catalog"),
+		"Pages": pagesRef,
+	})
+
+	return out.bytes(), nil
+}
+
+// Split returns a single-page PDF for each page of pdf, in order, e.g. to
+// deliver the invoices of a batch one by one. Links to other pages lead
+// nowhere in the split pages, and document-level data such as outlines
+// and metadata is not carried over.
+func Split(pdf []byte) ([][]byte, error) {
+	src, err := parsePDF(pdf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split PDF: %w", err)
+	}
+	if src.trailer["Encrypt"] != nil {
+		return nil, errors.New("failed to split PDF: document is encrypted")
+	}
+
+	pages, err := src.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to split PDF: %w", err)
+	}
+
+	split := make([][]byte, len(pages))
+	for i, page := range pages {
+		out := newPDFDocument()
+		out.version = src.version
+		pagesRef := out.add(nil)
+		pageRef := out.add(nil)
+
+		// The other pages map onto a null object, so that links to them
+		// don't drag them in
+		mapping := map[int]pdfRef{page.Num: pageRef}
+		missing := out.add(nil)
+		for _, other := range pages {
+			if other != page {
+				mapping[other.Num] = missing
+			}
+		}
+
+		dict := out.importObject(src, src.dict(page), mapping, "Parent").(pdfDict)
+		dict["Parent"] = pagesRef
+		out.objects[pageRef.Num] = dict
+
+		out.objects[pagesRef.Num] = pdfDict{
+			"Type":  pdfName("Pages"),
+			"Kids":  pdfArray{pageRef},
+			"Count": int64(1),
+		}
+		out.trailer["Root"] = out.add(pdfDict{
+			"Type":  pdfName("Catalog"),
+			"Pages": pagesRef,
+		})
+
+		split[i] = out.bytes()
+	}
+
+	return split, nil
+}
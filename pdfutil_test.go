@@ -0,0 +1,213 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Fixtures in testdata, one for each way PDFs store their objects
+var fixtures = []struct {
+	file  string
+	pages []string // Text of each page
+}{
+	// A cross-reference table, with resources inherited from the page tree
+	{"classic.pdf", []string{"Page 1", "Page 2"}},
+	// A compressed cross-reference stream
+	{"xrefstream.pdf", []string{"Page 1", "Page 2", "Page 3"}},
+	// Pages in an object stream, and an incremental update replacing the
+	// first page's content
+	{"objstream.pdf", []string{"Page 1 updated", "Page 2", "Page 3", "Page 4"}},
+}
+
+// namedPDF is a test input
+type namedPDF struct {
+	name string
+	data []byte
+}
+
+// malformed are inputs that are not valid PDFs
+var malformed = []namedPDF{
+	{"empty", nil},
+	{"not a PDF", []byte("<html><body>hello</body></html>")},
+	{"header only", []byte("%PDF-1.4\n")},
+	{"no catalog", []byte("%PDF-1.4\n1 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n")},
+	{"unterminated stream", []byte("%PDF-1.4\n1 0 obj\n<< /Length 3 >>\nstream\nabc")},
+	{"huge stream length", []byte("%PDF-1.4\n1 0 obj\n<< /Length 9223372036854775800 >>\nstream\nabc\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n")},
+}
+
+func readFixture(t *testing.T, file string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// textPattern matches the strings a content stream shows
+var textPattern = regexp.MustCompile(`\((.*?)\) Tj`)
+
+// pageTexts returns the text shown on each page of data
+func pageTexts(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		t.Fatalf("parsePDF() error = %v", err)
+	}
+	pages, err := doc.pages()
+	if err != nil {
+		t.Fatalf("pages() error = %v", err)
+	}
+
+	var texts []string
+	for _, page := range pages {
+		// Drawing over a page turns its content into an array of streams
+		contents, ok := doc.resolve(doc.dict(page)["Contents"]).(pdfArray)
+		if !ok {
+			contents = pdfArray{doc.dict(page)["Contents"]}
+		}
+
+		var text []string
+		for _, c := range contents {
+			stream, ok := doc.resolve(c).(*pdfStream)
+			if !ok {
+				t.Fatalf("page %d has no content stream", page.Num)
+			}
+
+			content := stream.Data
+			if stream.Dict["Filter"] == pdfName("FlateDecode") {
+				zr, err := zlib.NewReader(bytes.NewReader(content))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if content, err = io.ReadAll(zr); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, m := range textPattern.FindAllSubmatch(content, -1) {
+				text = append(text, string(m[1]))
+			}
+		}
+		texts = append(texts, strings.Join(text, " "))
+	}
+	return texts
+}
+
+func TestPageCount(t *testing.T) {
+	for _, f := range fixtures {
+		t.Run(f.file, func(t *testing.T) {
+			n, err := PageCount(readFixture(t, f.file))
+			if err != nil {
+				t.Fatalf("PageCount() error = %v", err)
+			}
+			if n != len(f.pages) {
+				t.Errorf("PageCount() = %d, want %d", n, len(f.pages))
+			}
+		})
+	}
+}
+
+func TestPageCountMalformed(t *testing.T) {
+	noCount := bytes.Replace(readFixture(t, "classic.pdf"), []byte("/Count 2"), []byte("/Total 2"), 1)
+	inputs := append(slices.Clone(malformed), namedPDF{"page tree without count", noCount})
+
+	for _, tt := range inputs {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := PageCount(tt.data); !errors.Is(err, errInvalidPDF) {
+				t.Errorf("PageCount() error = %v, want errInvalidPDF", err)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var pdfs [][]byte
+	var want []string
+	for _, f := range fixtures {
+		pdfs = append(pdfs, readFixture(t, f.file))
+		want = append(want, f.pages...)
+	}
+
+	merged, err := Merge(pdfs)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	if n, err := PageCount(merged); err != nil || n != len(want) {
+		t.Errorf("PageCount(merged) = %d, %v, want %d", n, err, len(want))
+	}
+	if got := pageTexts(t, merged); !slices.Equal(got, want) {
+		t.Errorf("merged pages = %q, want %q", got, want)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	classic := readFixture(t, "classic.pdf")
+	encrypted := bytes.Replace(classic, []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt << /Filter /Standard >>"), 1)
+
+	type mergeInput struct {
+		name string
+		pdfs [][]byte
+	}
+	tests := []mergeInput{
+		{"no PDFs", nil},
+		{"encrypted", [][]byte{classic, encrypted}},
+	}
+	for _, tt := range malformed {
+		tests = append(tests, mergeInput{tt.name, [][]byte{classic, tt.data}})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Merge(tt.pdfs); err == nil {
+				t.Error("Merge() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, f := range fixtures {
+		t.Run(f.file, func(t *testing.T) {
+			parts, err := Split(readFixture(t, f.file))
+			if err != nil {
+				t.Fatalf("Split() error = %v", err)
+			}
+			if len(parts) != len(f.pages) {
+				t.Fatalf("Split() returned %d PDFs, want %d", len(parts), len(f.pages))
+			}
+
+			for i, part := range parts {
+				if n, err := PageCount(part); err != nil || n != 1 {
+					t.Errorf("PageCount(part %d) = %d, %v, want 1", i, n, err)
+				}
+				if got := pageTexts(t, part); !slices.Equal(got, f.pages[i:i+1]) {
+					t.Errorf("part %d = %q, want %q", i, got, f.pages[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	encrypted := bytes.Replace(readFixture(t, "classic.pdf"), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt << /Filter /Standard >>"), 1)
+	inputs := append(slices.Clone(malformed), namedPDF{"encrypted", encrypted})
+
+	for _, tt := range inputs {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Split(tt.data); err == nil {
+				t.Error("Split() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// ErrPoolBusy is returned when no pool slot frees up within the acquire timeout
+var ErrPoolBusy = errors.New("all pool slots are busy")
+
+// Pool keeps a fixed number of warm Chrome instances and loans them out
+// to concurrent PDF generations. Every job runs in a fresh tab with its own
+// browser context, so nothing one document leaves behind, such as cookies,
+// storage or scripts still running, reaches the next.
+type Pool struct {
+	generator *Generator
+	slots     chan *slot
+	all       []*slot
+
+	acquireTimeout atomic.Int64
+	inUse          atomic.Int64
+	succeeded      atomic.Int64
+	failed         atomic.Int64
+}
+
+// PoolStats is a snapshot of a pool's usage
+type PoolStats struct {
+	Size      int   // Number of browser instances in the pool
+	InUse     int   // Instances currently rendering or opening the next job's tab
+	Idle      int   // Instances waiting for work
+	Succeeded int64 // Calls that returned a PDF since the pool was created
+	Failed    int64 // Calls that returned an error since the pool was created
+}
+
+// slot is a single browser instance owned by a pool, with a warm tab for
+// the next job
+type slot struct {
+	browser *browser
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// NewPool launches size browser instances, each with a warm tab. A browser
+// that crashes is relaunched without affecting the other instances.
+func NewPool(size int, options *PDFOptions) (*Pool, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	generator := NewGenerator(options)
+	if err := generator.options.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		generator: generator,
+		slots:     make(chan *slot, size),
+	}
+
+	for i := 0; i < size; i++ {
+		s := &slot{browser: newBrowser(generator.options)}
+		p.all = append(p.all, s)
+
+		if _, err := s.tab(); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to start pool: %w", err)
+		}
+		p.slots <- s
+	}
+
+	return p, nil
+}
+
+// SetAcquireTimeout makes calls fail with ErrPoolBusy when no instance frees
+// up within d. Zero, the default, waits until the caller's context is done.
+func (p *Pool) SetAcquireTimeout(d time.Duration) {
+	p.acquireTimeout.Store(int64(d))
+}
+
+// Stats returns a snapshot of the pool's usage
+func (p *Pool) Stats() PoolStats {
+	inUse := int(p.inUse.Load())
+	return PoolStats{
+		Size:      len(p.all),
+		InUse:     inUse,
+		Idle:      len(p.all) - inUse,
+		Succeeded: p.succeeded.Load(),
+		Failed:    p.failed.Load(),
+	}
+}
+
+// FromHTML generates a PDF from HTML content string using a pooled browser
+func (p *Pool) FromHTML(ctx context.Context, htmlContent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := p.run(ctx, p.generator.loadHTML(htmlContent), &buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FromURL generates a PDF from a URL using a pooled browser
+func (p *Pool) FromURL(ctx context.Context, url string) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := p.run(ctx, navigateTo(url), &buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF from URL: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Close shuts down every browser in the pool
+func (p *Pool) Close() {
+	for _, s := range p.all {
+		s.browser.close()
+	}
+}
+
+// run renders the page into w, retrying transient failures as the options
+// allow
+func (p *Pool) run(ctx context.Context, navigate chromedp.Action, w io.Writer) (int64, error) {
+	written, err := p.generator.retry(ctx, func() (int64, error) {
+		return p.attempt(ctx, navigate, w)
+	})
+	if err != nil {
+		p.failed.Add(1)
+	} else {
+		p.succeeded.Add(1)
+	}
+	return written, err
+}
+
+// attempt borrows a slot, renders the page on it into w and hands the slot
+// back
+func (p *Pool) attempt(ctx context.Context, navigate chromedp.Action, w io.Writer) (int64, error) {
+	s, err := p.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer p.release(s)
+
+	endLaunch := p.generator.span(ctx, spanLaunch)
+	tabCtx, err := s.tab()
+	endLaunch(err)
+	if err != nil {
+		return 0, err
+	}
+
+	runCtx, cancel := context.WithTimeout(tabCtx, p.generator.options.Timeout)
+	defer cancel()
+
+	// Abort the render as soon as the caller gives up
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	written, err := p.generator.render(withSpanParent(runCtx, ctx), navigate, w)
+
+	if ctx.Err() != nil {
+		return written, contextError(ctx)
+	}
+	if runCtx.Err() != nil {
+		return written, contextError(runCtx)
+	}
+
+	return written, err
+}
+
+// acquire waits for a free slot, honouring the acquire timeout
+func (p *Pool) acquire(ctx context.Context) (*slot, error) {
+	var timeout <-chan time.Time
+	if d := time.Duration(p.acquireTimeout.Load()); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case s := <-p.slots:
+		p.inUse.Add(1)
+		return s, nil
+	case <-timeout:
+		return nil, ErrPoolBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release closes the slot's tab, disposing of its browser context, and
+// returns the slot to the pool once a fresh tab is open for the next job.
+// The caller doesn't wait for the new tab.
+func (p *Pool) release(s *slot) {
+	go func() {
+		s.discard()
+		// A failure is left to the next job, which tries again
+		_, _ = s.tab()
+		p.inUse.Add(-1)
+		p.slots <- s
+	}()
+}
+
+// tab returns the slot's warm tab, opening one (and relaunching the
+// browser if needed) when there is none or it has gone away
+func (s *slot) tab() (context.Context, error) {
+	if s.ctx != nil && s.ctx.Err() == nil {
+		return s.ctx, nil
+	}
+
+	ctx, cancel, err := s.browser.newTab()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx, s.cancel = ctx, cancel
+	return ctx, nil
+}
+
+// discard closes the slot's tab, so the next job opens a new one
+func (s *slot) discard() {
+	if s.cancel != nil {
+		s.cancel()
+		s.ctx, s.cancel = nil, nil
+	}
+}
@@ -0,0 +1,87 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestPoolStatsCountsFailures(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ChromePath = "/nonexistent/chrome"
+
+	// NewPool would fail to launch the browser up front
+	s := &slot{browser: newBrowser(opts)}
+	p := &Pool{generator: NewGenerator(opts), slots: make(chan *slot, 1), all: []*slot{s}}
+	p.slots <- s
+	defer p.Close()
+
+	// The second call only gets the slot once the first handed it back
+	for range 2 {
+		if _, err := p.FromHTML(context.Background(), "<p>Hello</p>"); !errors.Is(err, ErrBrowserStart) {
+			t.Fatalf("FromHTML() error = %v, want ErrBrowserStart", err)
+		}
+	}
+
+	stats := p.Stats()
+	if stats.Succeeded != 0 || stats.Failed != 2 {
+		t.Errorf("Stats() = %+v, want 0 succeeded and 2 failed", stats)
+	}
+}
+
+func TestPoolIsolatesJobs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	var mu sync.Mutex
+	var cookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		mu.Unlock()
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "first", Path: "/"})
+		fmt.Fprint(w, `<html><body>Hello</body></html>`)
+	}))
+	defer server.Close()
+
+	p, err := NewPool(1, DefaultOptions())
+	if errors.Is(err, ErrBrowserStart) {
+		t.Skipf("Chrome is not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("NewPool() error = %v", err)
+	}
+	defer p.Close()
+
+	for range 2 {
+		if _, err := p.FromURL(context.Background(), server.URL); err != nil {
+			t.Fatalf("FromURL() error = %v", err)
+		}
+	}
+	if _, err := p.FromURL(context.Background(), "http://127.0.0.1:1/"); err == nil {
+		t.Fatal("FromURL() of a closed port succeeded")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, cookie := range cookies {
+		if cookie != "" {
+			t.Errorf("job %d sent cookie %q set by an earlier job", i+1, cookie)
+		}
+	}
+
+	stats := p.Stats()
+	if stats.Succeeded != 2 || stats.Failed != 1 {
+		t.Errorf("Stats() = %+v, want 2 succeeded and 1 failed", stats)
+	}
+}
@@ -0,0 +1,98 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// FromURLPost generates a PDF from the page url returns when body is POSTed
+// to it, e.g. a report service taking a JSON description of the report.
+// Headers, cookies and FailOnHTTPError apply as they do to FromURL.
+func (g *Generator) FromURLPost(url, contentType string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := g.run(context.Background(), postTo(url, contentType, body), &buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF from URL: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// postRequest is the body the document is requested with instead of a GET
+type postRequest struct {
+	url         string
+	contentType string
+	body        []byte
+	sent        bool
+}
+
+// postTo navigates the tab to url, having the interceptor turn the request
+// for the document into a POST of body
+func postTo(url, contentType string, body []byte) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		i, ok := ctx.Value(interceptorKey{}).(*interceptor)
+		if !ok {
+			return fmt.Errorf("failed to POST to %s: request interception is not available", url)
+		}
+
+		i.mu.Lock()
+		i.post = &postRequest{url: documentKey(url), contentType: contentType, body: body}
+		i.mu.Unlock()
+
+		// The options alone may not have needed interception
+		if err := i.enable().Do(ctx); err != nil {
+			return err
+		}
+
+		return navigateTo(url).Do(ctx)
+	})
+}
+
+// postFor returns the POST the request is to be turned into, the first time
+// the document is requested. Redirects of it are left alone.
+func (i *interceptor) postFor(ev *fetch.EventRequestPaused) *postRequest {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	post := i.post
+	if post == nil || post.sent || ev.ResourceType != network.ResourceTypeDocument ||
+		ev.RedirectedRequestID != "" || documentKey(ev.Request.URL) != post.url {
+		return nil
+	}
+	post.sent = true
+
+	return post
+}
+
+// rewrite turns the paused request into the POST, returning its headers
+// with the Content-Type of the body
+func (p *postRequest) rewrite(ev *fetch.EventRequestPaused, headers []*fetch.HeaderEntry) []*fetch.HeaderEntry {
+	ev.Request.Method = http.MethodPost
+	ev.Request.HasPostData = true
+	ev.Request.PostDataEntries = []*network.PostDataEntry{{Bytes: base64.StdEncoding.EncodeToString(p.body)}}
+
+	if headers == nil {
+		for name, value := range ev.Request.Headers {
+			headers = append(headers, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+
+	rewritten := make([]*fetch.HeaderEntry, 0, len(headers)+1)
+	for _, h := range headers {
+		if !strings.EqualFold(h.Name, "Content-Type") {
+			rewritten = append(rewritten, h)
+		}
+	}
+	if p.contentType != "" {
+		rewritten = append(rewritten, &fetch.HeaderEntry{Name: "Content-Type", Value: p.contentType})
+	}
+
+	return rewritten
+}
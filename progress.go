@@ -0,0 +1,72 @@
+package htmlgopdf
+
+import (
+	"context"
+	"io"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Stage is a phase of generation reported to OnProgress
+type Stage string
+
+// Generation stages, in the order they are reported. A retried render goes
+// through them again.
+const (
+	StageNavigating Stage = "navigating" // Loading the document
+	StageWaiting    Stage = "waiting"    // Waiting for the page to be ready
+	StagePrinting   Stage = "printing"   // Printing and receiving the PDF from Chrome
+	StageDone       Stage = "done"       // The PDF was written
+)
+
+// Progress is reported to OnProgress as generation moves along
+type Progress struct {
+	Stage Stage
+	Bytes int64 // PDF bytes received from Chrome so far, while printing
+}
+
+// progress reports p to OnProgress. A panic in the callback is reported
+// to OnWarning instead of failing the render.
+func (g *Generator) progress(p Progress) {
+	if g.options.OnProgress == nil {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			g.warn("OnProgress panicked: %v", r)
+		}
+	}()
+	g.options.OnProgress(p)
+}
+
+// stage reports that the render entered stage s
+func (g *Generator) stage(s Stage) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		g.progress(Progress{Stage: s})
+		return nil
+	})
+}
+
+// progressWriter reports the bytes written through it as printing progress
+type progressWriter struct {
+	w       io.Writer
+	g       *Generator
+	written int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.g.progress(Progress{Stage: StagePrinting, Bytes: p.written})
+	return n, err
+}
+
+// progressWriter wraps w to report printing progress, when there is a
+// callback to report it to
+func (g *Generator) progressWriter(w io.Writer) io.Writer {
+	if g.options.OnProgress == nil {
+		return w
+	}
+	return &progressWriter{w: w, g: g}
+}
@@ -0,0 +1,168 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// skipsFirstPageHeaderFooter reports whether the first page is printed
+// again without the header and footer
+func skipsFirstPageHeaderFooter(opts *PDFOptions) bool {
+	return opts.SkipFirstPageHeaderFooter && opts.DisplayHeaderFooter
+}
+
+// printFirstPage prints the first page of the PDF again, without the header
+// and footer. The margins stay, so the page is laid out the same.
+func (g *Generator) printFirstPage(ctx context.Context) ([]byte, error) {
+	params := g.printParams().
+		WithDisplayHeaderFooter(false).
+		WithPageRanges(strconv.Itoa(firstPage(g.options.PageRanges) + g.leadingPages()))
+
+	data, _, err := params.Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print first page: %w", err)
+	}
+	return data, nil
+}
+
+// renumbersHeaderFooter reports whether the pageNumber and totalPages of
+// the header and footer are shifted to StartPageNumber
+func renumbersHeaderFooter(opts *PDFOptions) bool {
+	if opts.StartPageNumber <= 1 || !opts.DisplayHeaderFooter {
+		return false
+	}
+	templates := opts.HeaderTemplate + opts.FooterTemplate
+	return strings.Contains(templates, "pageNumber") || strings.Contains(templates, "totalPages")
+}
+
+// leadingPages returns how many blank pages go before the document so that
+// Chrome, which always counts from 1 and doesn't run scripts in the
+// templates, numbers its first page StartPageNumber
+func (g *Generator) leadingPages() int {
+	if !renumbersHeaderFooter(g.options) {
+		return 0
+	}
+	return g.options.pageOffset()
+}
+
+// leadingPageClass marks the blank pages put before the document
+const leadingPageClass = "htmlgopdf-leading-page"
+
+// addLeadingPages puts n blank pages before the document. The page ranges
+// leave them out of the PDF, so it is still printed once.
+func addLeadingPages(ctx context.Context, n int) error {
+	script := fmt.Sprintf(`(() => {
+		for (let i = 0; i < %d; i++) {
+			const blank = document.createElement('div');
+			blank.className = %q;
+			blank.style.cssText = 'display:block;height:1px;margin:0;break-after:page';
+			document.body.prepend(blank);
+		}
+	})()`, n, leadingPageClass)
+
+	if err := chromedp.Evaluate(script, nil).Do(ctx); err != nil {
+		return fmt.Errorf("failed to add leading pages: %w", err)
+	}
+	return nil
+}
+
+// removeLeadingPages takes the blank pages addLeadingPages put before the
+// document away again
+func removeLeadingPages(ctx context.Context) error {
+	script := fmt.Sprintf(`document.querySelectorAll('.%s').forEach(e => e.remove())`, leadingPageClass)
+	return chromedp.Evaluate(script, nil).Do(ctx)
+}
+
+// printedRanges returns the page ranges to print, past the leading pages
+func (g *Generator) printedRanges() string {
+	return shiftPageRanges(g.options.PageRanges, g.leadingPages())
+}
+
+// shiftPageRanges moves valid page ranges offset pages on. Empty ranges,
+// which print every page, become every page after the first offset.
+func shiftPageRanges(ranges string, offset int) string {
+	if offset == 0 {
+		return ranges
+	}
+	if ranges == "" {
+		return strconv.Itoa(offset+1) + "-"
+	}
+
+	parts := strings.Split(ranges, ",")
+	for i, part := range parts {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		from, _ := parsePageNumber(first)
+		parts[i] = strconv.Itoa(from + offset)
+		if isRange {
+			to, _ := parsePageNumber(last)
+			parts[i] += "-" + strconv.Itoa(to+offset)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// replacePages puts the first page of each PDF in pages in place of the
+// page of data at its index. The pages keep their object numbers, so links
+// to them still work.
+func replacePages(data []byte, pages map[int][]byte) ([]byte, error) {
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace pages: %w", err)
+	}
+
+	targets, err := doc.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace pages: %w", err)
+	}
+
+	for i, page := range pages {
+		if i >= len(targets) {
+			continue
+		}
+		if err := replacePage(doc, targets[i], page); err != nil {
+			return nil, fmt.Errorf("failed to replace page %d: %w", i+1, err)
+		}
+	}
+
+	return doc.bytes(), nil
+}
+
+// replacePage puts the first page of page in place of the page of doc at
+// target
+func replacePage(doc *pdfDocument, target pdfRef, page []byte) error {
+	src, err := parsePDF(page)
+	if err != nil {
+		return err
+	}
+	srcPages, err := src.pages()
+	if err != nil {
+		return err
+	}
+	if len(srcPages) == 0 {
+		return nil
+	}
+
+	old := doc.dict(target)
+
+	// The old page's content isn't used by any other page
+	contents, ok := old["Contents"].(pdfArray)
+	if !ok {
+		contents = pdfArray{old["Contents"]}
+	}
+	for _, c := range contents {
+		if ref, ok := c.(pdfRef); ok {
+			delete(doc.objects, ref.Num)
+		}
+	}
+
+	mapping := map[int]pdfRef{srcPages[0].Num: target}
+	dict := doc.importObject(src, src.dict(srcPages[0]), mapping, "Parent").(pdfDict)
+	dict["Parent"] = old["Parent"]
+	doc.objects[target.Num] = dict
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/runtime"
+)
+
+// numberedFooter shows Chrome's page number and page count
+const numberedFooter = `<div style="font-size:9px"><span class="pageNumber"></span> / <span class="totalPages"></span></div>`
+
+func TestShiftPageRanges(t *testing.T) {
+	tests := []struct {
+		ranges string
+		offset int
+		want   string
+	}{
+		{"", 0, ""},
+		{"1-3", 0, "1-3"},
+		{"", 50, "51-"},
+		{"1", 50, "51"},
+		{"2-3", 50, "52-53"},
+		{"1-3, 5,7-9", 10, "11-13,15,17-19"},
+	}
+
+	for _, tt := range tests {
+		if got := shiftPageRanges(tt.ranges, tt.offset); got != tt.want {
+			t.Errorf("shiftPageRanges(%q, %d) = %q, want %q", tt.ranges, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestPrintedRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *OptionsBuilder
+		leading int
+		ranges  string
+	}{
+		{"numbered footer", WithOptions().HeaderFooter("", numberedFooter).StartPageNumber(51), 50, "51-"},
+		{"numbered footer with ranges", WithOptions().HeaderFooter("", numberedFooter).StartPageNumber(51).PageRange("2-3"), 50, "52-53"},
+		{"from page 1", WithOptions().HeaderFooter("", numberedFooter).StartPageNumber(1), 0, ""},
+		{"unnumbered footer", WithOptions().HeaderFooter("", `<div>Confidential</div>`).StartPageNumber(51), 0, ""},
+		{"no header or footer", WithOptions().StartPageNumber(51).PageRange("2"), 0, "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(tt.builder.options)
+			if got := g.leadingPages(); got != tt.leading {
+				t.Errorf("leadingPages() = %d, want %d", got, tt.leading)
+			}
+			if got := g.printParams().PageRanges; got != tt.ranges {
+				t.Errorf("PageRanges = %q, want %q", got, tt.ranges)
+			}
+		})
+	}
+}
+
+func TestAddLeadingPages(t *testing.T) {
+	recorder := &commandRecorder{}
+	ctx := cdp.WithExecutor(context.Background(), recorder)
+
+	if err := addLeadingPages(ctx, 50); err != nil {
+		t.Fatalf("addLeadingPages() error = %v", err)
+	}
+	if err := removeLeadingPages(ctx); err != nil {
+		t.Fatalf("removeLeadingPages() error = %v", err)
+	}
+
+	if len(recorder.commands) != 2 {
+		t.Fatalf("sent %q, want two evaluations", recorder.commands)
+	}
+	add := recorder.params[0].(*runtime.EvaluateParams).Expression
+	if !strings.Contains(add, "i < 50") || !strings.Contains(add, "break-after:page") {
+		t.Errorf("adding leading pages evaluated %s", add)
+	}
+	remove := recorder.params[1].(*runtime.EvaluateParams).Expression
+	if !strings.Contains(remove, leadingPageClass) {
+		t.Errorf("removing leading pages evaluated %s", remove)
+	}
+}
+
+func TestStartPageNumberPostProcessing(t *testing.T) {
+	opts := DefaultOptions()
+	opts.StartPageNumber = 51
+	opts.PageNumbers = &PageNumberOptions{}
+	opts.HeaderFooterParity = &HeaderFooterParityOptions{OddHeader: "Odd", EvenHeader: "Even"}
+
+	data, err := applyPageNumbers(readFixture(t, "classic.pdf"), opts)
+	if err != nil {
+		t.Fatalf("applyPageNumbers() error = %v", err)
+	}
+	if data, err = applyHeaderFooterParity(data, opts); err != nil {
+		t.Fatalf("applyHeaderFooterParity() error = %v", err)
+	}
+
+	want := []string{"Page 1 Page 51 of 52 Odd", "Page 2 Page 52 of 52 Even"}
+	got := pageTexts(t, data)
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("pages show %q, want %q", got, want)
+	}
+}
+
+func TestStartPageNumberSinglePrint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("launches Chrome")
+	}
+
+	html := `<div style="break-after:page">One</div><div style="break-after:page">Two</div><div>Three</div>`
+	pdf, err := WithOptions().
+		HeaderFooter("", numberedFooter).
+		StartPageNumber(51).
+		Generate(html)
+	if errors.Is(err, ErrBrowserStart) {
+		t.Skipf("Chrome is not available: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// The leading pages are left out
+	if n, err := PageCount(pdf); err != nil || n != 3 {
+		t.Errorf("PageCount() = %d, %v, want 3 pages", n, err)
+	}
+}
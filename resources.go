@@ -0,0 +1,150 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// ResourceError describes a subresource of the page, such as an image or a
+// stylesheet, that failed to load
+type ResourceError struct {
+	URL          string
+	StatusCode   int    // HTTP status of the response, or 0 when there was none
+	ErrorText    string // Chrome's network error, e.g. "net::ERR_NAME_NOT_RESOLVED", when there was no response
+	ResourceType string // CDP resource type, e.g. "Image", "Stylesheet" or "Font"
+}
+
+func (e *ResourceError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s %s returned HTTP %d", e.ResourceType, e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s failed to load: %s", e.ResourceType, e.URL, e.ErrorText)
+}
+
+// resourceWatcher records the subresources that fail to load
+type resourceWatcher struct {
+	fail   bool
+	ignore []string
+	report func(ResourceError)
+
+	mu       sync.Mutex
+	requests map[network.RequestID]*network.Request
+	failed   map[network.RequestID]bool
+	first    *ResourceError
+}
+
+// newResourceWatcher returns a watcher when the options report or fail on
+// resource errors, and nil otherwise
+func (g *Generator) newResourceWatcher() *resourceWatcher {
+	if !g.options.FailOnResourceError && g.options.OnResourceError == nil {
+		return nil
+	}
+
+	return &resourceWatcher{
+		fail:     g.options.FailOnResourceError,
+		ignore:   g.options.IgnoreResourceErrors,
+		report:   g.options.OnResourceError,
+		requests: make(map[network.RequestID]*network.Request),
+		failed:   make(map[network.RequestID]bool),
+	}
+}
+
+// watch starts recording failed subresources: responses with a 4xx or 5xx
+// status and requests that got no response. The main document is left to
+// FailOnHTTPError, and requests the page canceled or the network policy
+// blocked aren't failures.
+func (w *resourceWatcher) watch() chromedp.Action {
+	if w == nil {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return err
+		}
+		mainFrame := tree.Frame.ID
+
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			switch ev := ev.(type) {
+			case *network.EventRequestWillBeSent:
+				if ev.Type == network.ResourceTypeDocument && ev.FrameID == mainFrame {
+					return
+				}
+				w.mu.Lock()
+				w.requests[ev.RequestID] = ev.Request
+				w.mu.Unlock()
+			case *network.EventResponseReceived:
+				if ev.Response.Status >= 400 {
+					w.record(ev.RequestID, ResourceError{
+						URL:          ev.Response.URL,
+						StatusCode:   int(ev.Response.Status),
+						ResourceType: string(ev.Type),
+					})
+				}
+			case *network.EventLoadingFailed:
+				if ev.Canceled || ev.ErrorText == "net::ERR_BLOCKED_BY_CLIENT" {
+					return
+				}
+				w.record(ev.RequestID, ResourceError{
+					ErrorText:    ev.ErrorText,
+					ResourceType: string(ev.Type),
+				})
+			}
+		})
+		return nil
+	})
+}
+
+// record reports the failure of a subresource request once, unless its
+// URL matches IgnoreResourceErrors
+func (w *resourceWatcher) record(id network.RequestID, failure ResourceError) {
+	w.mu.Lock()
+	req, ok := w.requests[id]
+	if !ok || w.failed[id] {
+		// The main document, or a request that already failed
+		w.mu.Unlock()
+		return
+	}
+	if failure.URL == "" {
+		failure.URL = req.URL
+	}
+	for _, pattern := range w.ignore {
+		if matchWildcard(pattern, failure.URL) {
+			w.mu.Unlock()
+			return
+		}
+	}
+	w.failed[id] = true
+	if w.first == nil {
+		w.first = &failure
+	}
+	w.mu.Unlock()
+
+	if w.report != nil {
+		w.report(failure)
+	}
+}
+
+// check fails with the first subresource that failed to load when the
+// options fail on resource errors
+func (w *resourceWatcher) check() chromedp.Action {
+	if w == nil || !w.fail {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if w.first != nil {
+			return w.first
+		}
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Result is a generated PDF with metrics about how it was made, e.g. for
+// capacity planning. Durations are measured with the monotonic clock.
+type Result struct {
+	PDF     []byte
+	PDFSize int64  // Size of PDF in bytes
+	URL     string // Final URL after redirects, for renders of a URL
+
+	NavigationDuration time.Duration // Loading the document
+	WaitDuration       time.Duration // Waiting for the page to be ready, including wait conditions
+	PrintDuration      time.Duration // Printing with PrintToPDF and post-processing
+	TotalDuration      time.Duration // The whole call, including launching Chrome and retries
+
+	Console     []ConsoleMessage  // Console messages and uncaught exceptions of the page, with CaptureConsole
+	EvalResults []json.RawMessage // Results of the EvalBeforePrint expressions as JSON, null when undefined
+	Removed     map[string]int    // Number of elements each of RemoveSelectors removed
+}
+
+// resultKey is the context key of the Result a render records metrics in
+type resultKey struct{}
+
+// withResult returns ctx carrying res, for render to fill in
+func withResult(ctx context.Context, res *Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, res)
+}
+
+// resultFrom returns the Result carried by ctx, or nil
+func resultFrom(ctx context.Context) *Result {
+	res, _ := ctx.Value(resultKey{}).(*Result)
+	return res
+}
+
+// FromHTMLResult generates a PDF from HTML content string and reports how
+// long each phase took, and with CaptureConsole what the page logged. On
+// error the Result holds the durations of the phases that completed and
+// the console messages so far.
+func (g *Generator) FromHTMLResult(htmlContent string) (*Result, error) {
+	return g.htmlResult(context.Background(), htmlContent)
+}
+
+// FromURLResult generates a PDF from a URL and reports how long each phase
+// took, the final URL and with CaptureConsole what the page logged. On
+// error the Result holds the durations of the phases that completed and the
+// console messages so far.
+func (g *Generator) FromURLResult(url string) (*Result, error) {
+	return g.urlResult(context.Background(), url)
+}
+
+// htmlResult renders HTML content into a Result
+func (g *Generator) htmlResult(ctx context.Context, htmlContent string) (*Result, error) {
+	res, err := g.result(ctx, g.loadHTML(htmlContent))
+	// The document was about:blank or a temporary file
+	res.URL = ""
+	if err != nil {
+		return res, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	return res, nil
+}
+
+// urlResult renders a URL into a Result
+func (g *Generator) urlResult(ctx context.Context, url string) (*Result, error) {
+	res, err := g.result(ctx, navigateTo(url))
+	if err != nil {
+		return res, fmt.Errorf("failed to generate PDF from URL: %w", err)
+	}
+	return res, nil
+}
+
+// result runs a render, collecting the PDF and its metrics
+func (g *Generator) result(ctx context.Context, navigate chromedp.Action) (*Result, error) {
+	res := &Result{}
+	start := time.Now()
+
+	var buf bytes.Buffer
+	_, err := g.run(withResult(ctx, res), navigate, &buf)
+	res.TotalDuration = time.Since(start)
+	if err != nil {
+		return res, err
+	}
+
+	res.PDF = buf.Bytes()
+	res.PDFSize = int64(len(res.PDF))
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package htmlgopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// transientErrors are failures that usually go away when tried again
+var transientErrors = []string{
+	"chrome failed to start",
+	"websocket url timeout reached",
+	"target crashed",
+	"ERR_CONNECTION_RESET",
+	"ERR_CONNECTION_CLOSED",
+	"ERR_EMPTY_RESPONSE",
+	"ERR_NETWORK_CHANGED",
+}
+
+// retry calls attempt until it succeeds, fails permanently or runs out of
+// retries. Each call gets its own timeout, as attempt derives it from ctx.
+func (g *Generator) retry(ctx context.Context, attempt func() (int64, error)) (int64, error) {
+	backoff := g.options.RetryBackoff
+
+	for n := 1; ; n++ {
+		written, err := attempt()
+
+		// A PDF already partly written to w can't be taken back
+		if err == nil || written > 0 || n > g.options.Retries || ctx.Err() != nil || !transient(err) {
+			if err != nil && n > 1 {
+				err = fmt.Errorf("failed after %d attempts: %w", n, err)
+			}
+			return written, err
+		}
+
+		g.options.logger().Info("retrying after transient failure", "attempt", n, "backoff", backoff, "error", err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return 0, fmt.Errorf("failed after %d attempts: %w", n, err)
+		}
+		backoff *= 2
+	}
+}
+
+// transient reports whether err is worth another attempt. Anything not
+// known to be transient, such as an HTTPError, is treated as permanent.
+func transient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRemoteConnect) {
+		return true
+	}
+
+	for _, message := range transientErrors {
+		if strings.Contains(strings.ToLower(err.Error()), strings.ToLower(message)) {
+			return true
+		}
+	}
+	return false
+}
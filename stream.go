@@ -0,0 +1,49 @@
+package htmlgopdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/chromedp/cdproto/cdp"
+	cdpio "github.com/chromedp/cdproto/io"
+)
+
+// streamChunkSize is how many bytes are requested from Chrome per IO.read call
+const streamChunkSize = 1 << 20
+
+// copyStream copies a CDP IO stream to w chunk by chunk, returning the
+// number of bytes written
+func copyStream(ctx context.Context, stream cdpio.StreamHandle, w io.Writer) (int64, error) {
+	var written int64
+
+	for {
+		// Call IO.read directly, as the generated helper drops the
+		// base64Encoded flag we need to decode the chunk
+		var res cdpio.ReadReturns
+		params := cdpio.Read(stream).WithSize(streamChunkSize)
+		if err := cdp.Execute(ctx, cdpio.CommandRead, params, &res); err != nil {
+			return written, err
+		}
+
+		chunk := []byte(res.Data)
+		if res.Base64encoded {
+			decoded, err := base64.StdEncoding.DecodeString(res.Data)
+			if err != nil {
+				return written, fmt.Errorf("failed to decode stream chunk: %w", err)
+			}
+			chunk = decoded
+		}
+
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		if res.EOF {
+			return written, nil
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package htmlgopdf
+
+import (
+	"context"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Tracer records the phases of generation as spans. WithTelemetry, built
+// with the otel tag, sets one backed by OpenTelemetry.
+type Tracer interface {
+	// Start starts a span named name as a child of the span ctx carries, if
+	// any, and returns a function that ends it with the phase's error
+	Start(ctx context.Context, name string) (end func(err error))
+}
+
+// Span names of the phases of generation
+const (
+	spanLaunch   = "htmlgopdf.launch"   // Launching Chrome, or opening a tab on the persistent browser or a pool slot
+	spanNavigate = "htmlgopdf.navigate" // Loading the document
+	spanWait     = "htmlgopdf.wait"     // Waiting for the page to be ready
+	spanPrint    = "htmlgopdf.print"    // PrintToPDF and post-processing
+)
+
+// spanParentKey is the context key of the caller's context, whose span the
+// phases' spans are children of. Tabs of the persistent browser don't
+// derive from it, and neither do those of pools.
+type spanParentKey struct{}
+
+// withSpanParent returns ctx carrying parent, for the spans of a render
+func withSpanParent(ctx, parent context.Context) context.Context {
+	return context.WithValue(ctx, spanParentKey{}, parent)
+}
+
+// span starts a span named name when the options have a Tracer
+func (g *Generator) span(ctx context.Context, name string) func(err error) {
+	if g.options.Tracer == nil {
+		return func(error) {}
+	}
+
+	if parent, ok := ctx.Value(spanParentKey{}).(context.Context); ok {
+		ctx = parent
+	}
+	return g.options.Tracer.Start(ctx, name)
+}
+
+// traced runs action in a span named name
+func (g *Generator) traced(name string, action chromedp.Action) chromedp.Action {
+	if g.options.Tracer == nil {
+		return action
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		end := g.span(ctx, name)
+		err := action.Do(ctx)
+		end(err)
+		return err
+	})
+}
@@ -0,0 +1,47 @@
+package htmlgopdf
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/security"
+	"github.com/chromedp/chromedp"
+)
+
+// ignoreCertificateErrors makes the tab accept invalid TLS certificates when
+// IgnoreTLSErrors is set, warning once for every origin that served one
+func (g *Generator) ignoreCertificateErrors() chromedp.Action {
+	if !g.options.IgnoreTLSErrors {
+		return chromedp.Tasks{}
+	}
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var mu sync.Mutex
+		warned := make(map[string]bool)
+
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			response, ok := ev.(*network.EventResponseReceived)
+			if !ok || !strings.HasPrefix(response.Response.URL, "https:") {
+				return
+			}
+
+			switch response.Response.SecurityState {
+			case security.StateInsecure, security.StateInsecureBroken:
+				origin := originOf(response.Response.URL)
+
+				mu.Lock()
+				first := !warned[origin]
+				warned[origin] = true
+				mu.Unlock()
+
+				if first {
+					g.warn("ignored TLS certificate error for %s", origin)
+				}
+			}
+		})
+
+		return security.SetIgnoreCertificateErrors(true).Do(ctx)
+	})
+}
@@ -0,0 +1,280 @@
+package htmlgopdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// waitForAll waits, in parallel, until every selector is visible
+func waitForAll(selectors []string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var once sync.Once
+		var firstErr error
+
+		for _, selector := range selectors {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := chromedp.WaitVisible(selector).Do(ctx); err != nil {
+					once.Do(func() {
+						firstErr = fmt.Errorf("failed waiting for %q: %w", selector, err)
+						// No point waiting for the others
+						cancel()
+					})
+				}
+			}()
+		}
+		wg.Wait()
+
+		return firstErr
+	})
+}
+
+// waitForAny waits until one of the selectors is visible
+func waitForAny(selectors []string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		errs := make(chan error, len(selectors))
+		for _, selector := range selectors {
+			go func() {
+				errs <- chromedp.WaitVisible(selector).Do(ctx)
+			}()
+		}
+
+		var lastErr error
+		for range selectors {
+			err := <-errs
+			if err == nil {
+				// The remaining waits stop once ctx is cancelled
+				return nil
+			}
+			lastErr = err
+		}
+
+		return fmt.Errorf("failed waiting for any of %q: %w", selectors, lastErr)
+	})
+}
+
+// hiddenFunction reports whether every element matching selector is removed
+// or hidden, the way chromedp decides visibility
+const hiddenFunction = `(selector) => Array.from(document.querySelectorAll(selector)).every(
+	(el) => el.getClientRects().length === 0 || getComputedStyle(el).visibility === 'hidden')`
+
+// waitForHidden waits until no element matching selector is visible
+func waitForHidden(selector string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		err := chromedp.PollFunction(hiddenFunction, nil,
+			chromedp.WithPollingArgs(selector),
+			chromedp.WithPollingTimeout(0),
+		).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed waiting for %q to be hidden: %w", selector, err)
+		}
+		return nil
+	})
+}
+
+// functionPoll is how often WaitForFunction is evaluated when
+// WaitForFunctionPoll isn't set
+const functionPoll = 100 * time.Millisecond
+
+// functionState wraps an expression to report whether it is truthy and, for
+// error messages, its value as JSON, or as a string when it isn't JSON
+const functionState = `(async () => {
+	let value;
+	try {
+		value = await (%s);
+	} catch (e) {
+		return { ok: false, value: "threw " + e };
+	}
+	let json;
+	try {
+		json = JSON.stringify(value);
+	} catch (e) {}
+	return { ok: !!value, value: json === undefined ? String(value) : json };
+})()`
+
+// waitForFunction evaluates expression every poll until it is truthy. It
+// fails with ErrTimeout and the last value after timeout, or when timeout is
+// zero one poll before ctx's deadline, so the error isn't lost to ctx.
+func waitForFunction(expression string, poll, timeout time.Duration) chromedp.Action {
+	if poll <= 0 {
+		poll = functionPoll
+	}
+	script := fmt.Sprintf(functionState, expression)
+
+	return chromedp.ActionFunc(func(parent context.Context) error {
+		wait := timeout
+		if deadline, ok := parent.Deadline(); ok && wait <= 0 {
+			wait = time.Until(deadline) - poll
+		}
+		ctx := parent
+		if wait > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(parent, wait)
+			defer cancel()
+		}
+
+		ticker := time.NewTicker(poll)
+		defer ticker.Stop()
+
+		last := "undefined"
+		for {
+			var state struct {
+				OK    bool   `json:"ok"`
+				Value string `json:"value"`
+			}
+			err := chromedp.Evaluate(script, &state, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+				return p.WithAwaitPromise(true)
+			}).Do(ctx)
+			switch {
+			case err != nil && ctx.Err() == nil:
+				return fmt.Errorf("failed waiting for %q: %w", expression, err)
+			case err == nil && state.OK:
+				return nil
+			case err == nil:
+				last = state.Value
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				if parent.Err() != nil {
+					return parent.Err()
+				}
+				return fmt.Errorf("%w waiting for %q after %s, last value: %s", ErrTimeout, expression, wait.Round(time.Millisecond), last)
+			}
+		}
+	})
+}
+
+// fontsTimeout is how long WaitForFonts waits when WaitForFontsTimeout isn't
+// set
+const fontsTimeout = 5 * time.Second
+
+// fontsLoading waits up to %d milliseconds for the document's fonts and
+// returns the families still loading then, none when they are ready
+const fontsLoading = `Promise.race([
+	document.fonts.ready.then(() => []),
+	new Promise((resolve) => setTimeout(() => resolve(
+		[...new Set(Array.from(document.fonts)
+			.filter((f) => f.status === "loading")
+			.map((f) => f.family.replace(/^["']|["']$/g, "")))]
+	), %d)),
+])`
+
+// waitForFonts waits for web fonts to finish loading, warning about the
+// families still loading when they don't in time
+func (g *Generator) waitForFonts() chromedp.Action {
+	if !g.options.WaitForFonts {
+		return chromedp.Tasks{}
+	}
+
+	timeout := g.options.WaitForFontsTimeout
+	if timeout <= 0 {
+		timeout = fontsTimeout
+	}
+	script := fmt.Sprintf(fontsLoading, timeout.Milliseconds())
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var loading []string
+		err := chromedp.Evaluate(script, &loading, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed waiting for fonts: %w", err)
+		}
+
+		if len(loading) > 0 {
+			g.warn("fonts still loading after %s, printing anyway: %s", timeout, strings.Join(loading, ", "))
+		}
+		return nil
+	})
+}
+
+// imagesTimeout is how long WaitForImages waits when WaitForImagesTimeout
+// isn't set
+const imagesTimeout = 10 * time.Second
+
+// imagesLoading loads lazy images and CSS background images right away and
+// waits up to %d milliseconds for every image to finish, returning the ones
+// that failed and the ones still loading then
+const imagesLoading = `new Promise((resolve) => {
+	const deadline = Date.now() + %d;
+
+	for (const img of document.images) {
+		if (img.loading === "lazy") {
+			img.loading = "eager";
+		}
+	}
+
+	const backgrounds = new Set();
+	for (const el of document.querySelectorAll("*")) {
+		for (const [, url] of getComputedStyle(el).backgroundImage.matchAll(/url\(["']?(.*?)["']?\)/g)) {
+			backgrounds.add(url);
+		}
+	}
+	const backgroundImages = Array.from(backgrounds, (url) => {
+		const img = new Image();
+		img.src = url;
+		return img;
+	});
+
+	const src = (img) => img.currentSrc || img.src;
+	const check = () => {
+		const images = [...document.images, ...backgroundImages].filter(src);
+		const loading = images.filter((img) => !img.complete);
+		if (loading.length > 0 && Date.now() < deadline) {
+			setTimeout(check, 100);
+			return;
+		}
+		resolve({
+			broken: [...new Set(images.filter((img) => img.complete && img.naturalWidth === 0).map(src))],
+			loading: [...new Set(loading.map(src))],
+		});
+	};
+	check();
+})`
+
+// waitForImages waits for images to load or fail, warning about the broken
+// ones and the ones still loading when they don't finish in time
+func (g *Generator) waitForImages() chromedp.Action {
+	timeout := g.options.WaitForImagesTimeout
+	if timeout <= 0 {
+		timeout = imagesTimeout
+	}
+	script := fmt.Sprintf(imagesLoading, timeout.Milliseconds())
+
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		var images struct {
+			Broken  []string `json:"broken"`
+			Loading []string `json:"loading"`
+		}
+		err := chromedp.Evaluate(script, &images, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+			return p.WithAwaitPromise(true)
+		}).Do(ctx)
+		if err != nil {
+			return fmt.Errorf("failed waiting for images: %w", err)
+		}
+
+		if len(images.Broken) > 0 {
+			g.warn("images failed to load: %s", strings.Join(images.Broken, ", "))
+		}
+		if len(images.Loading) > 0 {
+			g.warn("images still loading after %s, printing anyway: %s", timeout, strings.Join(images.Loading, ", "))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,254 @@
+package htmlgopdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WatermarkOptions describes text drawn across every page of the PDF
+type WatermarkOptions struct {
+	Text    string  `json:"text"`
+	Font    string  `json:"font,omitempty"`    // One of the watermark fonts, Helvetica-Bold when empty
+	Size    float64 `json:"size,omitempty"`    // Font size in points, 72 when zero
+	Color   string  `json:"color,omitempty"`   // Hex color such as "#808080", grey when empty
+	Opacity float64 `json:"opacity,omitempty"` // From 0 to 1, 0.3 when zero
+	Angle   float64 `json:"angle,omitempty"`   // Counterclockwise rotation in degrees
+}
+
+// Resource names the watermark is drawn with, unlikely to clash with
+// Chrome's own
+const (
+	watermarkFont  = "HtmlgopdfWatermarkFont"
+	watermarkState = "HtmlgopdfWatermarkGS"
+)
+
+// watermarkFonts are the standard PDF fonts available to watermarks, which
+// every reader has so nothing needs to be embedded, with the widths of the
+// characters from space to ~ in thousandths of the font size
+var watermarkFonts = map[string][]int{
+	"Helvetica": {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	"Helvetica-Bold": {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+	"Courier":      nil, // Monospaced, see charWidth
+	"Courier-Bold": nil,
+}
+
+// validate checks the font and color
+func (w *WatermarkOptions) validate() error {
+	if w.Text == "" {
+		return fmt.Errorf("watermark text is empty")
+	}
+	if _, ok := watermarkFonts[w.Font]; w.Font != "" && !ok {
+		return fmt.Errorf("unknown watermark font %q: must be Helvetica, Helvetica-Bold, Courier or Courier-Bold", w.Font)
+	}
+	if w.Opacity < 0 || w.Opacity > 1 {
+		return fmt.Errorf("invalid watermark opacity %g: must be between 0 and 1", w.Opacity)
+	}
+	if _, err := parseHexColor(w.Color); w.Color != "" && err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyWatermark draws the watermark set in opts over every page of data
+func applyWatermark(data []byte, opts *PDFOptions) ([]byte, error) {
+	w := opts.Watermark
+	if w == nil {
+		return data, nil
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply watermark: %w", err)
+	}
+
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply watermark: %w", err)
+	}
+
+	font, size, opacity := w.Font, w.Size, w.Opacity
+	if font == "" {
+		font = "Helvetica-Bold"
+	}
+	if size == 0 {
+		size = 72
+	}
+	if opacity == 0 {
+		opacity = 0.3
+	}
+	rgb := [3]float64{0.5, 0.5, 0.5}
+	if w.Color != "" {
+		rgb, _ = parseHexColor(w.Color)
+	}
+
+	text := winAnsi(w.Text)
+	fontRef := doc.add(pdfDict{
+		"Type":     pdfName("Font"),
+		"Subtype":  pdfName("Type1"),
+		"BaseFont": pdfName(font),
+		"Encoding": pdfName("WinAnsiEncoding"),
+	})
+	stateRef := doc.add(pdfDict{
+		"Type": pdfName("ExtGState"),
+		"ca":   opacity,
+		"CA":   opacity,
+	})
+	// Keeps the page's own graphics state changes from leaking into the
+	// watermark, which is drawn after it
+	save := doc.add(&pdfStream{Dict: pdfDict{}, Data: []byte("q\n")})
+
+	var width float64
+	for _, c := range text {
+		width += float64(charWidth(font, c)) / 1000 * size
+	}
+	angle := w.Angle * math.Pi / 180
+	cos, sin := math.Cos(angle), math.Sin(angle)
+
+	for _, ref := range pages {
+		page := doc.dict(ref)
+
+		rect := mediaBox(doc, page)
+		cx, cy := (rect[0]+rect[2])/2, (rect[1]+rect[3])/2
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "Q\nq\n/%s gs\n%s %s %s rg\nBT\n/%s %s Tf\n", watermarkState,
+			formatReal(rgb[0]), formatReal(rgb[1]), formatReal(rgb[2]), watermarkFont, formatReal(size))
+		fmt.Fprintf(&content, "%s %s %s %s %s %s Tm\n", formatReal(cos), formatReal(sin),
+			formatReal(-sin), formatReal(cos), formatReal(cx), formatReal(cy))
+		// Center the text on the middle of the page, with the cap height
+		// roughly 0.7 of the size
+		fmt.Fprintf(&content, "%s %s Td\n", formatReal(-width/2), formatReal(-size*0.35))
+		writeString(&content, text)
+		content.WriteString(" Tj\nET\nQ\n")
+		watermark := doc.add(&pdfStream{Dict: pdfDict{}, Data: content.Bytes()})
+
+		drawOver(doc, page, save, watermark)
+
+		page["Resources"] = withResource(doc, page["Resources"], "Font", watermarkFont, fontRef)
+		page["Resources"] = withResource(doc, page["Resources"], "ExtGState", watermarkState, stateRef)
+	}
+
+	return doc.bytes(), nil
+}
+
+// drawOver makes overlay the last content of page, with save, a stream
+// saving the graphics state, put before the page's own content
+func drawOver(doc *pdfDocument, page pdfDict, save, overlay pdfRef) {
+	contents := pdfArray{save}
+	switch existing := page["Contents"].(type) {
+	case pdfArray:
+		contents = append(contents, existing...)
+	case nil:
+	default:
+		if array, ok := doc.resolve(existing).(pdfArray); ok {
+			contents = append(contents, array...)
+		} else {
+			contents = append(contents, existing)
+		}
+	}
+	page["Contents"] = append(contents, overlay)
+}
+
+// withResource returns a copy of resources with ref added to its category,
+// leaving resources shared with other pages untouched
+func withResource(doc *pdfDocument, resources pdfObject, category, name pdfName, ref pdfRef) pdfDict {
+	copied := pdfDict{}
+	for k, v := range doc.dict(resources) {
+		copied[k] = v
+	}
+
+	entries := pdfDict{}
+	for k, v := range doc.dict(copied[category]) {
+		entries[k] = v
+	}
+	entries[name] = ref
+	copied[category] = entries
+
+	return copied
+}
+
+// charWidth returns the width of c in font, in thousandths of the size
+func charWidth(font string, c byte) int {
+	widths := watermarkFonts[font]
+	if widths == nil {
+		return 600
+	}
+	if c >= ' ' && int(c-' ') < len(widths) {
+		return widths[c-' ']
+	}
+	// Accented letters are about as wide as the average letter
+	return 556
+}
+
+// winAnsi encodes s for a font with WinAnsiEncoding, replacing characters
+// it lacks with '?'. Latin-1 letters map to themselves.
+func winAnsi(s string) pdfString {
+	encoded := make(pdfString, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r > 0xFF || (r >= 0x7F && r < 0xA0) {
+			r = '?'
+		}
+		encoded = append(encoded, byte(r))
+	}
+	return encoded
+}
+
+// pdfNumber returns obj as a float64, or 0 when it isn't a number
+func pdfNumber(obj pdfObject) float64 {
+	switch v := obj.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// mediaBox returns the media box of page, or zeros when it has none
+func mediaBox(doc *pdfDocument, page pdfDict) [4]float64 {
+	box, _ := doc.resolve(page["MediaBox"]).(pdfArray)
+	var rect [4]float64
+	for i := 0; i < 4 && i < len(box); i++ {
+		rect[i] = pdfNumber(doc.resolve(box[i]))
+	}
+	return rect
+}
+
+// parseHexColor parses a color such as "#808080" or "#888" into RGB
+// components from 0 to 1
+func parseHexColor(s string) ([3]float64, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+
+	var rgb [3]float64
+	if len(hex) != 6 {
+		return rgb, fmt.Errorf("invalid color %q: must be like #808080", s)
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("invalid color %q: must be like #808080", s)
+		}
+		rgb[i] = float64(v) / 255
+	}
+	return rgb, nil
+}